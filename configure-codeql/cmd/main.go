@@ -1,5 +1,3 @@
-// TODO: Close existing PR's and cleanup branches in case of failure
-
 package main
 
 import (
@@ -7,11 +5,13 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/department-of-veterans-affairs/codeql-tools/configure-codeql/internal"
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
 	"github.com/department-of-veterans-affairs/codeql-tools/utils"
-	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -23,35 +23,48 @@ func init() {
 	}
 }
 
-type CustomFormatter struct{}
-
-func (f *CustomFormatter) Format(entry *logrus.Entry) ([]byte, error) {
-	if repoValue, ok := entry.Data["repo"]; ok {
-		repo := fmt.Sprint(repoValue)
-		if eventValue, ok := entry.Data["event"]; ok {
-			event := fmt.Sprint(eventValue)
-			return []byte(fmt.Sprintf("[%s]: [%s] %s\n", repo, event, entry.Message)), nil
-		}
-		return []byte(fmt.Sprintf("[%s]: %s\n", repo, entry.Message)), nil
-	}
-
-	return []byte(fmt.Sprintf("%s\n", entry.Message)), nil
-}
-
 func main() {
 	config := internal.ParseInput()
 
+	log.SetFormatter(&logging.Formatter{})
 	globalLogger := log.New()
-	globalLogger.SetFormatter(&CustomFormatter{})
+	globalLogger.SetFormatter(&logging.Formatter{})
+	summary := utils.NewSummary()
+	globalLogger.AddHook(summary)
+
+	stream, err := logging.NewStream("configure-codeql", config.EventsPath)
+	if err != nil {
+		globalLogger.Fatalf("%v", err)
+	}
+	defer stream.Close()
+	globalLogger.AddHook(stream.Hook(config.Org))
+
+	scheduler := internal.NewScheduler()
+	if !config.Enterprise {
+		err = run(config, globalLogger, scheduler)
+		if err != nil {
+			globalLogger.Fatalf("%v", err)
+		}
+		summary.Log(globalLogger)
+		utils.PublishReport(globalLogger, summary.Report("Configure CodeQL"), config.ReportPath)
+		return
+	}
 
-	globalLogger.Infof("Creating admin GitHub client")
-	adminClient := utils.NewGitHubClient(config.AdminToken)
+	ctx := context.Background()
+
+	globalLogger.Infof("Creating Configure CodeQL GitHub App client")
+	configureCodeQLClient, err := utils.NewGitHubAppClient(config.ConfigureCodeQLAppID, config.ConfigureCodeQLPrivateKey)
+	if err != nil {
+		globalLogger.Fatalf("failed to create Configure CodeQL GitHub App client: %v", err)
+	}
+	globalLogger.Debugf("Configure CodeQL GitHub App client created")
 
-	globalLogger.Infof("Creating Configure CodeQL GitHub Installation client")
-	configureCodeQLInstallationClient, err := utils.NewGitHubInstallationClient(config.ConfigureCodeQLAppID, config.ConfigureCodeQLInstallationID, config.ConfigureCodeQLPrivateKey)
+	globalLogger.Infof("Retrieving Configure CodeQL app installations")
+	installations, err := utils.ListOrganizationInstallations(ctx, configureCodeQLClient)
 	if err != nil {
-		globalLogger.Fatalf("failed to create EMASS GitHub App client: %v", err)
+		globalLogger.Fatalf("failed to list Configure CodeQL app installations: %v", err)
 	}
+	globalLogger.Debugf("Retrieved %d Configure CodeQL app installations", len(installations))
 
 	globalLogger.Infof("Creating Verify Scans GitHub App client")
 	verifyScansClient, err := utils.NewGitHubAppClient(config.VerifyScansAppID, config.VerifyScansPrivateKey)
@@ -60,53 +73,124 @@ func main() {
 	}
 	globalLogger.Debugf("Verify Scans GitHub App client created")
 
-	globalLogger.Infof("Creating Verify Scans GitHub App Installation client")
-	verifyScansInstallationClient, err := utils.NewGitHubInstallationClient(config.VerifyScansAppID, config.VerifyScansInstallationID, config.VerifyScansPrivateKey)
+	globalLogger.Infof("Retrieving Verify Scans app installations")
+	verifyScansInstallations, err := utils.ListOrganizationInstallations(ctx, verifyScansClient)
 	if err != nil {
-		globalLogger.Fatalf("failed to create Verify Scans GitHub App client: %v", err)
+		globalLogger.Fatalf("failed to list Verify Scans app installations: %v", err)
 	}
-	globalLogger.Debugf("Verify Scans GitHub App client created")
+	verifyScansInstallationIDs := utils.InstallationIDs(verifyScansInstallations)
+	globalLogger.Debugf("Retrieved %d Verify Scans app installations", len(verifyScansInstallations))
+
+	organizations := map[string]*utils.Summary{}
+	var failed []string
+	for _, installation := range installations {
+		verifyScansInstallationID, ok := verifyScansInstallationIDs[installation.Org]
+		if !ok {
+			globalLogger.Warnf("Verify Scans app is not installed in organization %s, skipping organization", installation.Org)
+			continue
+		}
 
-	m := &internal.Manager{
-		AdminGitHubClient:                 adminClient,
-		ConfigureCodeQLInstallationClient: configureCodeQLInstallationClient,
-		VerifyScansGithubClient:           verifyScansClient,
-		VerifyScansInstallationClient:     verifyScansInstallationClient,
+		inputs := map[string]string{
+			"configure_codeql_installation_id": strconv.FormatInt(installation.InstallationID, 10),
+			"verify_scans_installation_id":     strconv.FormatInt(verifyScansInstallationID, 10),
+		}
+		if _, ok := config.OrgOverrides[installation.Org]["plan_path"]; !ok {
+			inputs["plan_path"] = filepath.Join(filepath.Dir(config.PlanPath), fmt.Sprintf("%s-%s", installation.Org, filepath.Base(config.PlanPath)))
+		}
+		if _, ok := config.OrgOverrides[installation.Org]["status_path"]; !ok {
+			inputs["status_path"] = filepath.Join(filepath.Dir(config.StatusPath), fmt.Sprintf("%s-%s", installation.Org, filepath.Base(config.StatusPath)))
+		}
+		orgConfig := internal.ParseInputWithOverrides(utils.OrganizationInputs(config.OrgOverrides, installation.Org, inputs))
+
+		orgLogger, orgSummary := utils.NewOrganizationLogger(globalLogger, summary, installation.Org, &logging.Formatter{Org: installation.Org})
+		orgLogger.AddHook(stream.Hook(installation.Org))
+		organizations[installation.Org] = orgSummary
 
-		Config:       config,
-		Context:      context.Background(),
-		GlobalLogger: globalLogger,
+		globalLogger.Infof("Processing organization %s", installation.Org)
+		err = run(orgConfig, orgLogger, scheduler)
+		if err != nil {
+			globalLogger.Errorf("failed to process organization %s: %v", installation.Org, err)
+			failed = append(failed, installation.Org)
+			continue
+		}
+		orgSummary.Log(orgLogger)
+	}
+	for org := range config.OrgOverrides {
+		if _, ok := organizations[org]; !ok {
+			globalLogger.Warnf("Overrides configured for organization %s, but the Configure CodeQL app is not installed", org)
+		}
 	}
 
-	globalLogger.Infof("Querying verify-scans app for all installed repos")
-	installedRepos, err := m.ListVerifyScansInstalledRepos()
+	summary.Log(globalLogger)
+	utils.LogOrganizations(globalLogger, organizations, failed)
+
+	report := summary.Report("Configure CodeQL")
+	report.AddOrganizations(organizations, failed)
+	utils.PublishReport(globalLogger, report, config.ReportPath)
+}
+
+func run(config *internal.Input, globalLogger *log.Logger, scheduler *internal.Scheduler) error {
+	m, err := internal.NewManager(config, globalLogger, scheduler)
 	if err != nil {
-		globalLogger.Fatalf("failed to list installed repos: %v", err)
+		return err
 	}
-	m.VerifiedScansAppInstalledRepos = installedRepos
-	globalLogger.Debugf("found %d installed repos", len(installedRepos))
 
 	globalLogger.Infof("Retrieving repositories")
 	repos, err := m.ListRepos()
 	if err != nil {
-		globalLogger.Fatalf("failed to list repositories: %v", err)
+		return fmt.Errorf("failed to list repositories: %v", err)
 	}
 	globalLogger.Debugf("Retrieved %d repositories", len(repos))
 
+	if config.Mode != internal.ModeStatus {
+		globalLogger.Infof("Counting existing CodeQL workflow schedules with concurrency %d", config.Concurrency)
+		utils.ProcessConcurrently(repos, config.Concurrency, m.ObserveSchedules)
+		globalLogger.Debugf("Counted existing CodeQL workflow schedules")
+	}
+
+	process := m.ProcessRepository
+	switch config.Mode {
+	case internal.ModeFollowUp:
+		globalLogger.Infof("Following up on existing '%s' pull requests", internal.PullRequestTitle)
+		process = m.FollowUpRepository
+	case internal.ModeStatus:
+		globalLogger.Infof("Classifying the rollout status of each repository")
+		process = m.StatusRepository
+	}
+
 	if config.Repo == "" {
-		globalLogger.Infof("Processing all repos")
-		for _, repo := range repos {
-			m.ProcessRepository(repo)
-		}
+		globalLogger.Infof("Processing all repos with concurrency %d", config.Concurrency)
+		utils.ProcessConcurrently(repos, config.Concurrency, process)
 	} else {
 		globalLogger.WithField("repo", config.Repo).Infof("Processing single repo")
 		repo, resp, err := m.AdminGitHubClient.Repositories.Get(m.Context, config.Org, config.Repo)
 		if err != nil {
-			if resp.StatusCode == http.StatusNotFound {
-				globalLogger.Fatalf("repo does not exist: %v", err)
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("repo does not exist: %v", err)
 			}
-			globalLogger.Fatalf("failed to retrieve repository: %v", err)
+			return fmt.Errorf("failed to retrieve repository: %v", err)
+		}
+		process(repo)
+	}
+	if config.Mode == internal.ModeStatus {
+		status := internal.NewStatusReport(config.Org, m.Statuses)
+		status.Log(globalLogger)
+		globalLogger.Infof("Writing status for %d repositories to %s", status.Repositories, config.StatusPath)
+		err = status.Write(config.StatusPath)
+		if err != nil {
+			return fmt.Errorf("failed to write status: %v", err)
 		}
-		m.ProcessRepository(repo)
+		globalLogger.Debugf("Status written")
+		return nil
 	}
+	if config.PlanMode {
+		globalLogger.Infof("Writing plan for %d repositories to %s", len(m.Plans), config.PlanPath)
+		err = internal.WritePlans(config.PlanPath, m.Plans)
+		if err != nil {
+			return fmt.Errorf("failed to write plan: %v", err)
+		}
+		globalLogger.Debugf("Plan written")
+	}
+
+	return nil
 }
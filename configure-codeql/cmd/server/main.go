@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/configure-codeql/internal"
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	shutdownTimeout = 30 * time.Second
+)
+
+func init() {
+	log.SetLevel(log.InfoLevel)
+	debug := strings.ToLower(strings.TrimSpace(os.Getenv("DEBUG"))) == "true"
+	if debug {
+		log.SetLevel(log.DebugLevel)
+	}
+}
+
+func main() {
+	config := internal.ParseInput()
+	serverConfig := internal.ParseServerInput()
+
+	log.SetFormatter(&logging.Formatter{})
+	globalLogger := log.New()
+	globalLogger.SetFormatter(&logging.Formatter{})
+	summary := utils.NewSummary()
+	globalLogger.AddHook(summary)
+
+	stream, err := logging.NewStream("configure-codeql", config.EventsPath)
+	if err != nil {
+		globalLogger.Fatalf("%v", err)
+	}
+	defer stream.Close()
+	globalLogger.AddHook(stream.Hook(config.Org))
+
+	if config.Enterprise {
+		globalLogger.Fatalf("enterprise mode is not supported by the webhook server")
+	}
+	if config.PlanMode {
+		globalLogger.Fatalf("plan mode is not supported by the webhook server")
+	}
+	if config.Mode == internal.ModeStatus {
+		globalLogger.Fatalf("status mode is not supported by the webhook server")
+	}
+
+	m, err := internal.NewManager(config, globalLogger, internal.NewScheduler())
+	if err != nil {
+		globalLogger.Fatalf("%v", err)
+	}
+
+	globalLogger.Infof("Retrieving repositories")
+	repos, err := m.ListRepos()
+	if err != nil {
+		globalLogger.Fatalf("failed to list repositories: %v", err)
+	}
+	globalLogger.Debugf("Retrieved %d repositories", len(repos))
+
+	globalLogger.Infof("Counting existing CodeQL workflow schedules with concurrency %d", config.Concurrency)
+	utils.ProcessConcurrently(repos, config.Concurrency, m.ObserveSchedules)
+	globalLogger.Debugf("Counted existing CodeQL workflow schedules")
+
+	server := internal.NewServer(m, serverConfig.WebhookSecret, serverConfig.QueueSize)
+	server.Start()
+
+	mux := http.NewServeMux()
+	mux.Handle("/webhook", server)
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	httpServer := &http.Server{
+		Addr:              serverConfig.ListenAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		globalLogger.Infof("Listening for webhooks on %s", serverConfig.ListenAddress)
+		err := httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			globalLogger.Fatalf("failed to start webhook server: %v", err)
+		}
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	<-signals
+
+	globalLogger.Infof("Shutting down webhook server")
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err = httpServer.Shutdown(ctx)
+	if err != nil {
+		globalLogger.Errorf("failed to shut down webhook server: %v", err)
+	}
+
+	globalLogger.Infof("Waiting for queued repositories to be processed")
+	server.Shutdown()
+	globalLogger.Debugf("Webhook server shut down")
+
+	summary.Log(globalLogger)
+}
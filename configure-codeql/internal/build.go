@@ -0,0 +1,228 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CodeQLConfigPath = ".github/codeql-config.yml"
+
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+type BuildStepProposal struct {
+	Language   string
+	Marker     string
+	Steps      string
+	Confidence string
+	Reason     string
+}
+
+type CodeQLConfig struct {
+	BuildSteps map[string]string `yaml:"build_steps"`
+}
+
+type buildMarker struct {
+	languages  []string
+	matches    func(name string) bool
+	confidence string
+	steps      func(marker string, siblings []string) string
+}
+
+var buildMarkers = []buildMarker{
+	{
+		languages:  []string{"java", "kotlin"},
+		matches:    func(name string) bool { return name == "pom.xml" },
+		confidence: ConfidenceHigh,
+		steps: func(marker string, siblings []string) string {
+			return "mvn -B -DskipTests package"
+		},
+	},
+	{
+		languages:  []string{"java", "kotlin"},
+		matches:    func(name string) bool { return name == "build.gradle" || name == "build.gradle.kts" },
+		confidence: ConfidenceHigh,
+		steps: func(marker string, siblings []string) string {
+			if Contains(siblings, "gradlew") {
+				return "./gradlew build -x test --no-daemon"
+			}
+			return "gradle build -x test --no-daemon"
+		},
+	},
+	{
+		languages:  []string{"csharp"},
+		matches:    func(name string) bool { return strings.HasSuffix(name, ".sln") },
+		confidence: ConfidenceHigh,
+		steps: func(marker string, siblings []string) string {
+			return fmt.Sprintf("dotnet build %s", marker)
+		},
+	},
+	{
+		languages:  []string{"csharp"},
+		matches:    func(name string) bool { return strings.HasSuffix(name, ".csproj") },
+		confidence: ConfidenceMedium,
+		steps: func(marker string, siblings []string) string {
+			return fmt.Sprintf("dotnet build %s", marker)
+		},
+	},
+	{
+		languages:  []string{"c", "cpp"},
+		matches:    func(name string) bool { return name == "CMakeLists.txt" },
+		confidence: ConfidenceHigh,
+		steps: func(marker string, siblings []string) string {
+			return "cmake -S . -B build && cmake --build build"
+		},
+	},
+	{
+		languages:  []string{"c", "cpp"},
+		matches:    func(name string) bool { return name == "Makefile" },
+		confidence: ConfidenceMedium,
+		steps: func(marker string, siblings []string) string {
+			return "make"
+		},
+	},
+	{
+		languages:  []string{"go"},
+		matches:    func(name string) bool { return name == "go.mod" },
+		confidence: ConfidenceHigh,
+		steps: func(marker string, siblings []string) string {
+			return "go build ./..."
+		},
+	},
+	{
+		languages:  []string{"swift"},
+		matches:    func(name string) bool { return name == "Package.swift" },
+		confidence: ConfidenceHigh,
+		steps: func(marker string, siblings []string) string {
+			return "swift build"
+		},
+	},
+}
+
+type markerCandidate struct {
+	rule  int
+	path  string
+	depth int
+}
+
+func DetectBuildSteps(paths, languages []string) []BuildStepProposal {
+	directories := map[string][]string{}
+	for _, file := range paths {
+		if isVendoredPath(file) {
+			continue
+		}
+		directory := path.Dir(file)
+		directories[directory] = append(directories[directory], path.Base(file))
+	}
+
+	var proposals []BuildStepProposal
+	for _, language := range languages {
+		var candidates []markerCandidate
+		for i, rule := range buildMarkers {
+			if !Contains(rule.languages, language) {
+				continue
+			}
+			for _, file := range paths {
+				if !isVendoredPath(file) && rule.matches(path.Base(file)) {
+					candidates = append(candidates, markerCandidate{
+						rule:  i,
+						path:  file,
+						depth: strings.Count(file, "/"),
+					})
+				}
+			}
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+		sort.Slice(candidates, func(i, j int) bool {
+			if candidates[i].depth != candidates[j].depth {
+				return candidates[i].depth < candidates[j].depth
+			}
+			if candidates[i].rule != candidates[j].rule {
+				return candidates[i].rule < candidates[j].rule
+			}
+			return candidates[i].path < candidates[j].path
+		})
+
+		selected := candidates[0]
+		rule := buildMarkers[selected.rule]
+		directory := path.Dir(selected.path)
+		marker := path.Base(selected.path)
+		steps := rule.steps(marker, directories[directory])
+		if directory != "." {
+			steps = fmt.Sprintf("cd %s && %s", directory, steps)
+		}
+
+		confidence := rule.confidence
+		reason := fmt.Sprintf("Found `%s`", selected.path)
+		if selected.depth > 0 {
+			confidence = lowerConfidence(confidence)
+			reason += " outside the repository root"
+		}
+		ambiguous := 0
+		for _, candidate := range candidates[1:] {
+			if candidate.depth == selected.depth {
+				ambiguous++
+			}
+		}
+		if ambiguous == 1 {
+			confidence = lowerConfidence(confidence)
+			reason += ", another build file was found at the same level"
+		} else if ambiguous > 1 {
+			confidence = lowerConfidence(confidence)
+			reason += fmt.Sprintf(", %d other build files were found at the same level", ambiguous)
+		}
+
+		proposals = append(proposals, BuildStepProposal{
+			Language:   language,
+			Marker:     selected.path,
+			Steps:      steps,
+			Confidence: confidence,
+			Reason:     reason,
+		})
+	}
+
+	return proposals
+}
+
+func GenerateCodeQLConfig(proposals []BuildStepProposal) (string, error) {
+	config := CodeQLConfig{
+		BuildSteps: map[string]string{},
+	}
+	for _, proposal := range proposals {
+		config.BuildSteps[proposal.Language] = proposal.Steps
+	}
+
+	configBytes, err := yaml.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal codeql-config.yml: %w", err)
+	}
+
+	return string(configBytes), nil
+}
+
+func lowerConfidence(confidence string) string {
+	if confidence == ConfidenceHigh {
+		return ConfidenceMedium
+	}
+
+	return ConfidenceLow
+}
+
+func isVendoredPath(file string) bool {
+	for _, segment := range strings.Split(file, "/") {
+		if segment == "vendor" || segment == "node_modules" || segment == "third_party" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectBuildSteps(t *testing.T) {
+	tests := []struct {
+		name      string
+		paths     []string
+		languages []string
+		proposals []BuildStepProposal
+	}{
+		{
+			name:      "maven",
+			paths:     []string{"pom.xml", "src/main/java/App.java"},
+			languages: []string{"java"},
+			proposals: []BuildStepProposal{
+				{Language: "java", Marker: "pom.xml", Steps: "mvn -B -DskipTests package", Confidence: ConfidenceHigh, Reason: "Found `pom.xml`"},
+			},
+		},
+		{
+			name:      "gradle with wrapper",
+			paths:     []string{"build.gradle.kts", "gradlew", "src/main/kotlin/App.kt"},
+			languages: []string{"kotlin"},
+			proposals: []BuildStepProposal{
+				{Language: "kotlin", Marker: "build.gradle.kts", Steps: "./gradlew build -x test --no-daemon", Confidence: ConfidenceHigh, Reason: "Found `build.gradle.kts`"},
+			},
+		},
+		{
+			name:      "gradle without wrapper",
+			paths:     []string{"build.gradle", "src/main/java/App.java"},
+			languages: []string{"java"},
+			proposals: []BuildStepProposal{
+				{Language: "java", Marker: "build.gradle", Steps: "gradle build -x test --no-daemon", Confidence: ConfidenceHigh, Reason: "Found `build.gradle`"},
+			},
+		},
+		{
+			name:      "maven and gradle at the root",
+			paths:     []string{"build.gradle", "pom.xml"},
+			languages: []string{"java"},
+			proposals: []BuildStepProposal{
+				{Language: "java", Marker: "pom.xml", Steps: "mvn -B -DskipTests package", Confidence: ConfidenceMedium, Reason: "Found `pom.xml`, another build file was found at the same level"},
+			},
+		},
+		{
+			name:      "dotnet solution",
+			paths:     []string{"App.sln", "src/App/App.csproj"},
+			languages: []string{"csharp"},
+			proposals: []BuildStepProposal{
+				{Language: "csharp", Marker: "App.sln", Steps: "dotnet build App.sln", Confidence: ConfidenceHigh, Reason: "Found `App.sln`"},
+			},
+		},
+		{
+			name:      "dotnet project",
+			paths:     []string{"App.csproj", "Program.cs"},
+			languages: []string{"csharp"},
+			proposals: []BuildStepProposal{
+				{Language: "csharp", Marker: "App.csproj", Steps: "dotnet build App.csproj", Confidence: ConfidenceMedium, Reason: "Found `App.csproj`"},
+			},
+		},
+		{
+			name:      "cmake",
+			paths:     []string{"CMakeLists.txt", "Makefile", "src/main.c"},
+			languages: []string{"cpp"},
+			proposals: []BuildStepProposal{
+				{Language: "cpp", Marker: "CMakeLists.txt", Steps: "cmake -S . -B build && cmake --build build", Confidence: ConfidenceMedium, Reason: "Found `CMakeLists.txt`, another build file was found at the same level"},
+			},
+		},
+		{
+			name:      "make",
+			paths:     []string{"Makefile", "main.c"},
+			languages: []string{"c"},
+			proposals: []BuildStepProposal{
+				{Language: "c", Marker: "Makefile", Steps: "make", Confidence: ConfidenceMedium, Reason: "Found `Makefile`"},
+			},
+		},
+		{
+			name:      "go module",
+			paths:     []string{"go.mod", "main.go", "vendor/example.com/lib/go.mod"},
+			languages: []string{"go"},
+			proposals: []BuildStepProposal{
+				{Language: "go", Marker: "go.mod", Steps: "go build ./...", Confidence: ConfidenceHigh, Reason: "Found `go.mod`"},
+			},
+		},
+		{
+			name:      "swift package",
+			paths:     []string{"Package.swift", "Sources/App/main.swift"},
+			languages: []string{"swift"},
+			proposals: []BuildStepProposal{
+				{Language: "swift", Marker: "Package.swift", Steps: "swift build", Confidence: ConfidenceHigh, Reason: "Found `Package.swift`"},
+			},
+		},
+		{
+			name:      "build file outside the repository root",
+			paths:     []string{"service/pom.xml", "web/package.json"},
+			languages: []string{"java"},
+			proposals: []BuildStepProposal{
+				{Language: "java", Marker: "service/pom.xml", Steps: "cd service && mvn -B -DskipTests package", Confidence: ConfidenceMedium, Reason: "Found `service/pom.xml` outside the repository root"},
+			},
+		},
+		{
+			name:      "low confidence fallback",
+			paths:     []string{"native/a/Makefile", "native/b/Makefile", "native/c/Makefile"},
+			languages: []string{"c"},
+			proposals: []BuildStepProposal{
+				{Language: "c", Marker: "native/a/Makefile", Steps: "cd native/a && make", Confidence: ConfidenceLow, Reason: "Found `native/a/Makefile` outside the repository root, 2 other build files were found at the same level"},
+			},
+		},
+		{
+			name:      "vendored build files only",
+			paths:     []string{"vendor/lib/pom.xml", "node_modules/pkg/CMakeLists.txt", "third_party/go.mod"},
+			languages: []string{"java", "cpp", "go"},
+		},
+		{
+			name:      "language without build files",
+			paths:     []string{"requirements.txt", "app.py", "pom.xml"},
+			languages: []string{"python"},
+		},
+		{
+			name:      "multiple languages",
+			paths:     []string{"go.mod", "api/pom.xml"},
+			languages: []string{"go", "java"},
+			proposals: []BuildStepProposal{
+				{Language: "go", Marker: "go.mod", Steps: "go build ./...", Confidence: ConfidenceHigh, Reason: "Found `go.mod`"},
+				{Language: "java", Marker: "api/pom.xml", Steps: "cd api && mvn -B -DskipTests package", Confidence: ConfidenceMedium, Reason: "Found `api/pom.xml` outside the repository root"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			proposals := DetectBuildSteps(test.paths, test.languages)
+			if !reflect.DeepEqual(proposals, test.proposals) {
+				t.Errorf("got proposals %+v, want %+v", proposals, test.proposals)
+			}
+		})
+	}
+}
+
+func TestGenerateCodeQLConfig(t *testing.T) {
+	config, err := GenerateCodeQLConfig([]BuildStepProposal{
+		{Language: "java", Steps: "mvn -B -DskipTests package"},
+		{Language: "go", Steps: "go build ./..."},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "build_steps:\n    go: go build ./...\n    java: mvn -B -DskipTests package\n"
+	if config != expected {
+		t.Errorf("got config %q, want %q", config, expected)
+	}
+}
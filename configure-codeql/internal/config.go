@@ -4,93 +4,263 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/sethvargo/go-githubactions"
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
 )
 
 const (
-	PullRequestTitle = "Action Required: Configure CodeQL"
-	SourceBranchName = "ghas-enforcement-codeql"
-	SourceRepo       = "department-of-veterans-affairs/codeql-tools"
+	ModeConfigure = "configure"
+	ModeFollowUp  = "follow-up"
+	ModeStatus    = "status"
+
+	PullRequestMarker = "<!-- configure-codeql -->"
+	PullRequestTitle  = "Action Required: Configure CodeQL"
+	SourceBranchName  = "ghas-enforcement-codeql"
+	SourceRepo        = "department-of-veterans-affairs/codeql-tools"
 )
 
 func ParseInput() *Input {
-	adminToken := githubactions.GetInput("admin_token")
+	return ParseInputWithOverrides(nil)
+}
+
+func ParseInputWithOverrides(overrides map[string]string) *Input {
+	action := utils.NewInputAction(overrides)
+
+	enterprise := strings.ToLower(strings.TrimSpace(action.GetInput("enterprise"))) == "true"
+
+	adminToken := action.GetInput("admin_token")
 	if adminToken == "" {
-		githubactions.Fatalf("admin_token input is required")
+		action.Fatalf("admin_token input is required")
+	}
+
+	commitAuthorEmail := action.GetInput("commit_author_email")
+
+	commitAuthorName := action.GetInput("commit_author_name")
+	if (commitAuthorName == "") != (commitAuthorEmail == "") {
+		action.Fatalf("commit_author_name and commit_author_email inputs must be set together")
 	}
 
-	configureCodeQLAppID := githubactions.GetInput("configure_codeql_app_id")
+	commitMessage := action.GetInput("commit_message")
+	if commitMessage == "" {
+		commitMessage = "Configure CodeQL"
+	}
+
+	concurrency := action.GetInput("concurrency")
+	if concurrency == "" {
+		concurrency = "1"
+	}
+
+	configureCodeQLAppID := action.GetInput("configure_codeql_app_id")
 	if configureCodeQLAppID == "" {
-		githubactions.Fatalf("configure_codeql_app_id input is required")
+		action.Fatalf("configure_codeql_app_id input is required")
 	}
 
-	configureCodeQLPrivateKey := githubactions.GetInput("configure_codeql_private_key")
+	configureCodeQLPrivateKey := action.GetInput("configure_codeql_private_key")
 	if configureCodeQLPrivateKey == "" {
-		githubactions.Fatalf("configure_codeql_private_key input is required")
+		action.Fatalf("configure_codeql_private_key input is required")
 	}
 
-	configureCodeQLInstallationID := githubactions.GetInput("configure_codeql_installation_id")
-	if configureCodeQLInstallationID == "" {
-		githubactions.Fatalf("configure_codeql_installation_id input is required")
+	configureCodeQLInstallationID := action.GetInput("configure_codeql_installation_id")
+	if configureCodeQLInstallationID == "" && !enterprise {
+		action.Fatalf("configure_codeql_installation_id input is required")
+	}
+
+	emassDocumentationURL := action.GetInput("emass_documentation_url")
+
+	emassInventoryPath := action.GetInput("emass_inventory_path")
+
+	emassInventoryRepo := action.GetInput("emass_inventory_repo")
+	if emassInventoryRepo != "" && emassInventoryPath == "" {
+		action.Fatalf("emass_inventory_path input is required when emass_inventory_repo is set")
 	}
 
-	org := githubactions.GetInput("org")
-	if org == "" {
-		githubactions.Fatalf("org input is required")
+	escalationThresholdDays := action.GetInput("escalation_threshold_days")
+	if escalationThresholdDays == "" {
+		escalationThresholdDays = "45"
 	}
 
-	pullRequestBody := githubactions.GetInput("pull_request_body")
+	gmailFrom := action.GetInput("gmail_from")
+
+	gmailPassword := action.GetInput("gmail_password")
+	if gmailFrom != "" && gmailPassword == "" {
+		action.Fatalf("gmail_password input is required when gmail_from is set")
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(action.GetInput("mode")))
+	if mode == "" {
+		mode = ModeConfigure
+	}
+	if mode != ModeConfigure && mode != ModeFollowUp && mode != ModeStatus {
+		action.Fatalf("mode input must be one of: %s, %s, %s", ModeConfigure, ModeFollowUp, ModeStatus)
+	}
+
+	org := action.GetInput("org")
+	if org == "" && !enterprise {
+		action.Fatalf("org input is required")
+	}
+
+	orgOverrides, err := utils.ParseOrgOverrides(action.GetInput("org_overrides"))
+	if err != nil {
+		action.Fatalf("invalid org_overrides input: %v", err)
+	}
+
+	policyPath := action.GetInput("policy_path")
+	if policyPath == "" {
+		policyPath = ".github/codeql-rollout-policy.yml"
+	}
+
+	policyRepo := action.GetInput("policy_repo")
+
+	pullRequestBody := action.GetInput("pull_request_body")
 	if pullRequestBody == "" {
-		githubactions.Fatalf("pull_request_body input is required")
+		action.Fatalf("pull_request_body input is required")
 	}
 
-	verifyScansAppID := githubactions.GetInput("verify_scans_app_id")
+	verifyScansAppID := action.GetInput("verify_scans_app_id")
 	if verifyScansAppID == "" {
-		githubactions.Fatalf("verify_scans_app_id input is required")
+		action.Fatalf("verify_scans_app_id input is required")
 	}
 
-	verifyScansPrivateKey := githubactions.GetInput("verify_scans_private_key")
+	verifyScansPrivateKey := action.GetInput("verify_scans_private_key")
 	if verifyScansPrivateKey == "" {
-		githubactions.Fatalf("verify_scans_private_key input is required")
+		action.Fatalf("verify_scans_private_key input is required")
 	}
 
-	verifyScansInstallationID := githubactions.GetInput("verify_scans_installation_id")
-	if verifyScansInstallationID == "" {
-		githubactions.Fatalf("verify_scans_installation_id input is required")
+	verifyScansInstallationID := action.GetInput("verify_scans_installation_id")
+	if verifyScansInstallationID == "" && !enterprise {
+		action.Fatalf("verify_scans_installation_id input is required")
 	}
 
-	repo := githubactions.GetInput("repo")
+	planMode := strings.ToLower(strings.TrimSpace(action.GetInput("plan"))) == "true"
+
+	planPath := action.GetInput("plan_path")
+	if planPath == "" {
+		planPath = "plan.json"
+	}
+
+	reminderIntervalDays := action.GetInput("reminder_interval_days")
+	if reminderIntervalDays == "" {
+		reminderIntervalDays = "14"
+	}
+
+	repo := action.GetInput("repo")
+
+	reportPath := action.GetInput("report_path")
+	if reportPath == "" {
+		reportPath = "report.json"
+	}
+
+	eventsPath := action.GetInput("events_path")
+
+	exemptionProperty := strings.TrimSpace(action.GetInput("exemption_property"))
+
+	statusPath := action.GetInput("status_path")
+	if statusPath == "" {
+		statusPath = "status.json"
+	}
+
+	concurrencyInt, err := strconv.Atoi(concurrency)
+	if err != nil || concurrencyInt < 1 {
+		action.Fatalf("concurrency input must be a positive integer: %s", concurrency)
+	}
+
+	escalationThresholdDaysInt, err := strconv.Atoi(escalationThresholdDays)
+	if err != nil || escalationThresholdDaysInt < 1 {
+		action.Fatalf("escalation_threshold_days input must be a positive integer: %s", escalationThresholdDays)
+	}
+
+	reminderIntervalDaysInt, err := strconv.Atoi(reminderIntervalDays)
+	if err != nil || reminderIntervalDaysInt < 1 {
+		action.Fatalf("reminder_interval_days input must be a positive integer: %s", reminderIntervalDays)
+	}
 
 	configureCodeQLAppIDInt64, err := strconv.ParseInt(configureCodeQLAppID, 10, 64)
 	if err != nil {
-		githubactions.Fatalf("configure_codeql_app_id input must be an integer: %v", err)
+		action.Fatalf("configure_codeql_app_id input must be an integer: %v", err)
 	}
 
-	configureCodeQLInstallationIDInt64, err := strconv.ParseInt(configureCodeQLInstallationID, 10, 64)
-	if err != nil {
-		githubactions.Fatalf("configure_codeql_installation_id input must be an integer: %v", err)
+	var configureCodeQLInstallationIDInt64 int64
+	if configureCodeQLInstallationID != "" {
+		configureCodeQLInstallationIDInt64, err = strconv.ParseInt(configureCodeQLInstallationID, 10, 64)
+		if err != nil {
+			action.Fatalf("configure_codeql_installation_id input must be an integer: %v", err)
+		}
 	}
 
 	verifyScansAppIDInt64, err := strconv.ParseInt(verifyScansAppID, 10, 64)
 	if err != nil {
-		githubactions.Fatalf("verify_scans_app_id input must be an integer: %v", err)
+		action.Fatalf("verify_scans_app_id input must be an integer: %v", err)
 	}
 
-	verifyScansInstallationIDInt64, err := strconv.ParseInt(verifyScansInstallationID, 10, 64)
-	if err != nil {
-		githubactions.Fatalf("verify_scans_installation_id input must be an integer: %v", err)
+	var verifyScansInstallationIDInt64 int64
+	if verifyScansInstallationID != "" {
+		verifyScansInstallationIDInt64, err = strconv.ParseInt(verifyScansInstallationID, 10, 64)
+		if err != nil {
+			action.Fatalf("verify_scans_installation_id input must be an integer: %v", err)
+		}
 	}
 
 	return &Input{
 		AdminToken:                    adminToken,
+		CommitAuthorEmail:             commitAuthorEmail,
+		CommitAuthorName:              commitAuthorName,
+		CommitMessage:                 commitMessage,
+		Concurrency:                   concurrencyInt,
+		EMASSDocumentationURL:         emassDocumentationURL,
+		EMASSInventoryPath:            emassInventoryPath,
+		EMASSInventoryRepo:            emassInventoryRepo,
+		Enterprise:                    enterprise,
+		EscalationThresholdDays:       escalationThresholdDaysInt,
+		EventsPath:                    eventsPath,
+		ExemptionProperty:             exemptionProperty,
+		GmailFrom:                     gmailFrom,
+		GmailPassword:                 gmailPassword,
+		Mode:                          mode,
 		ConfigureCodeQLAppID:          configureCodeQLAppIDInt64,
 		ConfigureCodeQLPrivateKey:     []byte(configureCodeQLPrivateKey),
 		ConfigureCodeQLInstallationID: configureCodeQLInstallationIDInt64,
 		Org:                           strings.ToLower(org),
+		OrgOverrides:                  orgOverrides,
+		PlanMode:                      planMode,
+		PlanPath:                      planPath,
+		PolicyPath:                    policyPath,
+		PolicyRepo:                    policyRepo,
 		PullRequestBody:               pullRequestBody,
+		ReminderIntervalDays:          reminderIntervalDaysInt,
 		Repo:                          strings.ToLower(repo),
+		ReportPath:                    reportPath,
+		StatusPath:                    statusPath,
 		VerifyScansAppID:              verifyScansAppIDInt64,
 		VerifyScansPrivateKey:         []byte(verifyScansPrivateKey),
 		VerifyScansInstallationID:     verifyScansInstallationIDInt64,
 	}
 }
+
+func ParseServerInput() *ServerInput {
+	action := utils.NewInputAction(nil)
+
+	listenAddress := action.GetInput("listen_address")
+	if listenAddress == "" {
+		listenAddress = ":8080"
+	}
+
+	queueSize := action.GetInput("queue_size")
+	if queueSize == "" {
+		queueSize = "1000"
+	}
+
+	webhookSecret := action.GetInput("webhook_secret")
+	if webhookSecret == "" {
+		action.Fatalf("webhook_secret input is required")
+	}
+
+	queueSizeInt, err := strconv.Atoi(queueSize)
+	if err != nil || queueSizeInt < 1 {
+		action.Fatalf("queue_size input must be a positive integer: %s", queueSize)
+	}
+
+	return &ServerInput{
+		ListenAddress: listenAddress,
+		QueueSize:     queueSizeInt,
+		WebhookSecret: []byte(webhookSecret),
+	}
+}
@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	DotNetFrameworkRunner = "windows-latest"
+
+	maxDotNetProjectFiles = 20
+)
+
+var dotNetFrameworkPattern = regexp.MustCompile(`(?i)<TargetFrameworkVersion>|<TargetFrameworks?>[^<]*\bnet4\d`)
+
+func (m *Manager) UsesDotNetFramework(owner, repo, ref string) (bool, error) {
+	paths, _, err := m.ListRepositoryTree(owner, repo, ref)
+	if err != nil {
+		return false, err
+	}
+
+	projects, packagesConfig := DotNetProjectFiles(paths)
+	if packagesConfig {
+		return true, nil
+	}
+	for i, project := range projects {
+		if i == maxDotNetProjectFiles {
+			break
+		}
+		content, found, err := m.GetFileContents(owner, repo, project, ref)
+		if err != nil {
+			return false, err
+		}
+		if found && IsDotNetFrameworkProject(content) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func DotNetProjectFiles(paths []string) ([]string, bool) {
+	var projects []string
+	packagesConfig := false
+	for _, file := range paths {
+		if isVendoredPath(file) {
+			continue
+		}
+		name := strings.ToLower(path.Base(file))
+		if name == "packages.config" {
+			packagesConfig = true
+		}
+		if strings.HasSuffix(name, ".csproj") {
+			projects = append(projects, file)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		depthI, depthJ := strings.Count(projects[i], "/"), strings.Count(projects[j], "/")
+		if depthI != depthJ {
+			return depthI < depthJ
+		}
+		return projects[i] < projects[j]
+	})
+
+	return projects, packagesConfig
+}
+
+func IsDotNetFrameworkProject(content string) bool {
+	return dotNetFrameworkPattern.MatchString(content)
+}
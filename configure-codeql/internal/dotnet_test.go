@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIsDotNetFrameworkProject(t *testing.T) {
+	tests := []struct {
+		name      string
+		content   string
+		framework bool
+	}{
+		{
+			name:      "legacy project",
+			content:   `<Project ToolsVersion="15.0"><PropertyGroup><TargetFrameworkVersion>v4.7.2</TargetFrameworkVersion></PropertyGroup></Project>`,
+			framework: true,
+		},
+		{
+			name:      "sdk project targeting net48",
+			content:   `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>net48</TargetFramework></PropertyGroup></Project>`,
+			framework: true,
+		},
+		{
+			name:      "multi-targeted project",
+			content:   `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFrameworks>netstandard2.0;net462</TargetFrameworks></PropertyGroup></Project>`,
+			framework: true,
+		},
+		{
+			name:    "modern dotnet",
+			content: `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>net8.0</TargetFramework></PropertyGroup></Project>`,
+		},
+		{
+			name:    "netstandard",
+			content: `<Project Sdk="Microsoft.NET.Sdk"><PropertyGroup><TargetFramework>netstandard2.0</TargetFramework></PropertyGroup></Project>`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if framework := IsDotNetFrameworkProject(test.content); framework != test.framework {
+				t.Errorf("got %t, want %t", framework, test.framework)
+			}
+		})
+	}
+}
+
+func TestDotNetProjectFiles(t *testing.T) {
+	tests := []struct {
+		name           string
+		paths          []string
+		projects       []string
+		packagesConfig bool
+	}{
+		{
+			name:     "projects sorted by depth",
+			paths:    []string{"src/Api/Api.csproj", "Tool.csproj", "src/App.csproj", "README.md"},
+			projects: []string{"Tool.csproj", "src/App.csproj", "src/Api/Api.csproj"},
+		},
+		{
+			name:           "packages config",
+			paths:          []string{"src/Web/packages.config", "src/Web/Web.csproj"},
+			projects:       []string{"src/Web/Web.csproj"},
+			packagesConfig: true,
+		},
+		{
+			name:  "vendored files are ignored",
+			paths: []string{"vendor/packages.config", "node_modules/lib/Lib.csproj"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			projects, packagesConfig := DotNetProjectFiles(test.paths)
+			if !reflect.DeepEqual(projects, test.projects) {
+				t.Errorf("got projects %v, want %v", projects, test.projects)
+			}
+			if packagesConfig != test.packagesConfig {
+				t.Errorf("got packages.config %t, want %t", packagesConfig, test.packagesConfig)
+			}
+		})
+	}
+}
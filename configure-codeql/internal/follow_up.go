@@ -0,0 +1,242 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
+	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	EscalationMarker = "<!-- configure-codeql-escalation -->"
+	ReminderMarker   = "<!-- configure-codeql-reminder -->"
+)
+
+func (m *Manager) FollowUpRepository(repo *github.Repository) {
+	logger := m.GlobalLogger.WithField("repo", repo.GetName())
+
+	org := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+
+	if org != m.Config.Org {
+		logger.Debugf("skipping repo %s, not in org %s", repo.GetFullName(), m.Config.Org)
+		return
+	}
+
+	plan := NewRepositoryPlan(name, repo.GetDefaultBranch())
+	plan.Status = PlanStatusFollowUp
+	if m.Config.PlanMode {
+		defer m.addPlan(plan)
+	}
+
+	if repo.GetArchived() {
+		plan.Skip("skipped-archived")
+		logger.Debugf("Repository is archived, skipping")
+		return
+	}
+
+	logger.Infof("Retrieving all '%s' pull requests", PullRequestTitle)
+	pullRequests, err := m.ListEnablementPullRequests(org, name, "all")
+	if err != nil {
+		plan.Status = PlanStatusError
+		logger.Errorf("failed to retrieve pull requests, skipping repo: %v", err)
+		return
+	}
+	if len(pullRequests) == 0 {
+		plan.Skip("skipped-no-pull-request")
+		logger.Debugf("No enablement pull requests found, skipping repository")
+		return
+	}
+	logger.Debugf("Retrieved %d pull requests", len(pullRequests))
+
+	var openPullRequests []*github.PullRequest
+	for _, pullRequest := range pullRequests {
+		if pullRequest.GetState() == "open" {
+			openPullRequests = append(openPullRequests, pullRequest)
+		}
+	}
+
+	if len(openPullRequests) == 0 {
+		latest := LatestPullRequest(pullRequests)
+		if latest.MergedAt != nil {
+			logger.WithFields(log.Fields{
+				"event":                   logging.EventFollowUpMerged,
+				logging.DetailPullRequest: latest.GetNumber(),
+			}).Infof("Pull request #%d was merged on %s", latest.GetNumber(), latest.GetMergedAt().Format(time.DateOnly))
+			m.MigrateDefaultSetup(repo, latest, logger, plan)
+			return
+		}
+
+		logger.WithFields(log.Fields{
+			"event":                   logging.EventFollowUpClosedUnmerged,
+			logging.DetailPullRequest: latest.GetNumber(),
+		}).Warnf("Pull request #%d was closed without merging on %s", latest.GetNumber(), latest.GetClosedAt().Format(time.DateOnly))
+		m.retryClosedPullRequest(repo, logger)
+		return
+	}
+
+	pullRequest, _ := SelectEnablementPullRequest(openPullRequests)
+	_, err = m.RebaseConflictingPullRequest(repo, pullRequest, logger, plan)
+	if err != nil {
+		logger.Errorf("failed to resolve conflicts of pull request #%d, skipping repo: %v", pullRequest.GetNumber(), err)
+		return
+	}
+
+	createdAt := pullRequest.GetCreatedAt().Time
+	age := daysSince(createdAt)
+
+	logger.Infof("Retrieving comments on pull request #%d", pullRequest.GetNumber())
+	comments, err := m.ListPullRequestComments(org, name, pullRequest.GetNumber())
+	if err != nil {
+		logger.Errorf("failed to retrieve pull request comments, skipping repo: %v", err)
+		return
+	}
+	lastReminder := createdAt
+	reminders := 0
+	escalated := false
+	for _, comment := range comments {
+		if strings.Contains(comment.GetBody(), ReminderMarker) {
+			reminders++
+			if comment.GetCreatedAt().After(lastReminder) {
+				lastReminder = comment.GetCreatedAt().Time
+			}
+		}
+		if strings.Contains(comment.GetBody(), EscalationMarker) {
+			escalated = true
+		}
+	}
+	logger.Debugf("Pull request #%d is %d days old with %d reminders", pullRequest.GetNumber(), age, reminders)
+
+	if age >= m.Config.EscalationThresholdDays && !escalated {
+		logger.Infof("Pull request #%d has been open for %d days, escalating", pullRequest.GetNumber(), age)
+		err = m.escalatePullRequest(repo, pullRequest, age, logger, plan)
+		if err != nil {
+			logger.Errorf("failed to escalate pull request, skipping repo: %v", err)
+			return
+		}
+		logger.WithFields(log.Fields{
+			"event":                   logging.EventFollowUpEscalated,
+			logging.DetailPullRequest: pullRequest.GetNumber(),
+		}).Infof("Escalated pull request #%d open for %d days", pullRequest.GetNumber(), age)
+		return
+	}
+
+	if daysSince(lastReminder) >= m.Config.ReminderIntervalDays {
+		logger.Infof("Posting reminder on pull request #%d", pullRequest.GetNumber())
+		body := fmt.Sprintf("This pull request has been open for %d days. Please review and merge it to enable CodeQL scanning for this repository, or reply here if changes are needed before it can be merged.\n\n%s", age, ReminderMarker)
+		if m.Config.PlanMode {
+			plan.AddIntent(IntentCreateComment, fmt.Sprintf("#%d", pullRequest.GetNumber()), map[string]string{
+				"body": body,
+			})
+		} else {
+			err = m.CreateComment(org, name, pullRequest.GetNumber(), body)
+			if err != nil {
+				logger.Errorf("failed to post reminder, skipping repo: %v", err)
+				return
+			}
+		}
+		logger.WithField("event", logging.EventFollowUpReminded).Infof("Posted reminder %d on pull request #%d open for %d days", reminders+1, pullRequest.GetNumber(), age)
+		return
+	}
+
+	logger.WithFields(log.Fields{
+		"event":                   logging.EventFollowUpOpen,
+		logging.DetailPullRequest: pullRequest.GetNumber(),
+	}).Infof("Pull request #%d has been open for %d days", pullRequest.GetNumber(), age)
+}
+
+func (m *Manager) escalatePullRequest(repo *github.Repository, pullRequest *github.PullRequest, age int, logger *log.Entry, plan *RepositoryPlan) error {
+	org := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+
+	admins, err := m.ListRepositoryAdmins(org, name)
+	if err != nil {
+		return err
+	}
+	var mentions []string
+	for _, admin := range admins {
+		mentions = append(mentions, fmt.Sprintf("@%s", admin))
+	}
+
+	body := fmt.Sprintf("This pull request has been open for %d days without being merged. CodeQL scanning is required for this repository and this pull request has been escalated", age)
+	if len(mentions) > 0 {
+		body += fmt.Sprintf(" to the repository administrators: %s", strings.Join(mentions, " "))
+	}
+	body += fmt.Sprintf(".\n\n%s", EscalationMarker)
+
+	if m.Config.PlanMode {
+		plan.AddIntent(IntentCreateComment, fmt.Sprintf("#%d", pullRequest.GetNumber()), map[string]string{
+			"body": body,
+		})
+	} else {
+		err = m.CreateComment(org, name, pullRequest.GetNumber(), body)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = m.notifySystemOwner(repo, pullRequest, age, plan)
+	if err != nil {
+		logger.Warnf("Failed to email the system owner about pull request #%d, the pull request will not be escalated again: %v", pullRequest.GetNumber(), err)
+	}
+
+	return nil
+}
+
+func (m *Manager) notifySystemOwner(repo *github.Repository, pullRequest *github.PullRequest, age int, plan *RepositoryPlan) error {
+	if m.Config.GmailFrom == "" {
+		return nil
+	}
+	emass, err := m.GetEMASSConfig(repo.GetOwner().GetLogin(), repo.GetName(), pullRequest.GetHead().GetRef())
+	if err != nil {
+		return err
+	}
+	if emass == nil || !strings.Contains(emass.SystemOwnerEmail, "@") {
+		return nil
+	}
+
+	subject := "Action Required: CodeQL Enablement Pull Request Awaiting Merge"
+	emailBody := fmt.Sprintf("The CodeQL enablement pull request for %s has been open for %d days without being merged: %s", repo.GetHTMLURL(), age, pullRequest.GetHTMLURL())
+	if m.Config.PlanMode {
+		plan.AddIntent(IntentSendEmail, emass.SystemOwnerEmail, map[string]string{
+			"subject": subject,
+		})
+		return nil
+	}
+
+	return utils.SendGmail(m.Config.GmailFrom, m.Config.GmailPassword, "", []string{emass.SystemOwnerEmail}, subject, emailBody)
+}
+
+func (m *Manager) retryClosedPullRequest(repo *github.Repository, logger *log.Entry) {
+	retry, err := m.ClosedPullRequestRetryable(repo, logger)
+	if err != nil {
+		logger.Errorf("failed to analyze workflows, skipping repo: %v", err)
+		return
+	}
+	if !retry {
+		return
+	}
+	logger.WithField("event", logging.EventFollowUpRetryScheduled).Infof("Repository will be configured again on the next configure run")
+}
+
+func (m *Manager) ClosedPullRequestRetryable(repo *github.Repository, logger *log.Entry) (bool, error) {
+	logger.Infof("Checking if repository was configured without the pull request")
+	workflowAnalysis, err := m.AnalyzeWorkflows(repo.GetOwner().GetLogin(), repo.GetName(), repo.GetDefaultBranch())
+	if err != nil {
+		return false, err
+	}
+	if len(workflowAnalysis.ReusableWorkflows()) > 0 {
+		logger.Debugf("Reusable workflow in use, not retrying closed pull request")
+		return false, nil
+	}
+
+	return true, nil
+}
+
+func daysSince(t time.Time) int {
+	return int(time.Since(t).Hours() / 24)
+}
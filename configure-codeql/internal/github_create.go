@@ -2,28 +2,64 @@ package internal
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 
 	"github.com/google/go-github/v52/github"
 )
 
-func (m *Manager) CreateFile(owner, repo, branch, path, message, content string) error {
-	content = base64.StdEncoding.EncodeToString([]byte(content))
-	opts := &github.RepositoryContentFileOptions{
+func (m *Manager) CreateCommit(owner, repo, parentSHA, message string, files []GeneratedFile) (string, error) {
+	parent, _, err := m.AdminGitHubClient.Git.GetCommit(m.Context, owner, repo, parentSHA)
+	if err != nil {
+		return "", fmt.Errorf("failed to get parent commit: %v", err)
+	}
+
+	var entries []*github.TreeEntry
+	for _, file := range files {
+		blob, _, err := m.AdminGitHubClient.Git.CreateBlob(m.Context, owner, repo, &github.Blob{
+			Content:  github.String(file.Content),
+			Encoding: github.String("utf-8"),
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to create blob for %s: %v", file.Path, err)
+		}
+
+		entries = append(entries, &github.TreeEntry{
+			Path: github.String(file.Path),
+			Mode: github.String("100644"),
+			Type: github.String("blob"),
+			SHA:  blob.SHA,
+		})
+	}
+
+	tree, _, err := m.AdminGitHubClient.Git.CreateTree(m.Context, owner, repo, parent.GetTree().GetSHA(), entries)
+	if err != nil {
+		return "", fmt.Errorf("failed to create tree: %v", err)
+	}
+
+	commit := &github.Commit{
 		Message: github.String(message),
-		Content: []byte(content),
-		Branch:  github.String(branch),
+		Tree:    tree,
+		Parents: []*github.Commit{
+			{
+				SHA: github.String(parentSHA),
+			},
+		},
 	}
-	_, _, err := m.AdminGitHubClient.Repositories.CreateFile(m.Context, owner, repo, path, opts)
+	if m.Config.CommitAuthorName != "" && m.Config.CommitAuthorEmail != "" {
+		commit.Author = &github.CommitAuthor{
+			Name:  github.String(m.Config.CommitAuthorName),
+			Email: github.String(m.Config.CommitAuthorEmail),
+		}
+	}
+	result, _, err := m.AdminGitHubClient.Git.CreateCommit(m.Context, owner, repo, commit)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %v", err)
+		return "", fmt.Errorf("failed to create commit: %v", err)
 	}
 
-	return nil
+	return result.GetSHA(), nil
 }
 
-func (m *Manager) CreatePullRequest(owner, repo, head, base, title, body string) error {
+func (m *Manager) CreatePullRequest(owner, repo, head, base, title, body string) (int, error) {
 	pr := &github.NewPullRequest{
 		Title:               github.String(title),
 		Head:                github.String(head),
@@ -31,12 +67,12 @@ func (m *Manager) CreatePullRequest(owner, repo, head, base, title, body string)
 		Body:                github.String(body),
 		MaintainerCanModify: github.Bool(true),
 	}
-	_, _, err := m.ConfigureCodeQLInstallationClient.PullRequests.Create(context.Background(), owner, repo, pr)
+	pullRequest, _, err := m.ConfigureCodeQLInstallationClient.PullRequests.Create(context.Background(), owner, repo, pr)
 	if err != nil {
-		return fmt.Errorf("failed to create pull request: %w", err)
+		return 0, fmt.Errorf("failed to create pull request: %w", err)
 	}
 
-	return nil
+	return pullRequest.GetNumber(), nil
 }
 
 func (m *Manager) CreateRef(owner, repo, branch, sha string) error {
@@ -53,3 +89,14 @@ func (m *Manager) CreateRef(owner, repo, branch, sha string) error {
 
 	return nil
 }
+
+func (m *Manager) CreateComment(owner, repo string, number int, body string) error {
+	_, _, err := m.ConfigureCodeQLInstallationClient.Issues.CreateComment(m.Context, owner, repo, number, &github.IssueComment{
+		Body: &body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create comment: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,15 @@
+package internal
+
+import (
+	"fmt"
+)
+
+func (m *Manager) DeleteRef(owner, repo, branch string) error {
+	ref := fmt.Sprintf("heads/%s", branch)
+	_, err := m.AdminGitHubClient.Git.DeleteRef(m.Context, owner, repo, ref)
+	if err != nil {
+		return fmt.Errorf("failed to delete ref: %v", err)
+	}
+
+	return nil
+}
@@ -1,7 +1,9 @@
 package internal
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/http"
 
 	"github.com/google/go-github/v52/github"
 )
@@ -16,13 +18,86 @@ func (m *Manager) GetDefaultRefSHA(owner, repo, branch string) (string, error) {
 	return ref.GetObject().GetSHA(), nil
 }
 
-func (m *Manager) GetFileSHA(owner, repo, branch, path string) (string, error) {
-	fileContent, _, _, err := m.ConfigureCodeQLInstallationClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{
-		Ref: fmt.Sprintf("refs/heads/%s", branch),
+func (m *Manager) GetFileContents(owner, repo, path, ref string) (string, bool, error) {
+	fileContent, _, resp, err := m.ConfigureCodeQLInstallationClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to get file: %v", err)
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return "", false, nil
+		}
+
+		return "", false, fmt.Errorf("failed to get file: %v", err)
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return "", false, fmt.Errorf("failed to decode file content: %v", err)
+	}
+
+	return content, true, nil
+}
+
+func (m *Manager) GetPullRequest(owner, repo string, number int) (*github.PullRequest, error) {
+	pullRequest, _, err := m.ConfigureCodeQLInstallationClient.PullRequests.Get(m.Context, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request: %v", err)
+	}
+
+	return pullRequest, nil
+}
+
+func (m *Manager) GetSecurityAndAnalysis(owner, repo string) (*github.SecurityAndAnalysis, error) {
+	repository, _, err := m.AdminGitHubClient.Repositories.Get(m.Context, owner, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repository: %v", err)
+	}
+
+	return repository.GetSecurityAndAnalysis(), nil
+}
+
+func (m *Manager) GetRolloutPolicy(owner, repo, path string) (*RolloutPolicy, error) {
+	fileContent, _, _, err := m.AdminGitHubClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rollout policy: %v", err)
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode rollout policy: %v", err)
+	}
+
+	return ParseRolloutPolicy(content)
+}
+
+func (m *Manager) GetEMASSInventory(owner, repo, path string) (*EMASSInventory, error) {
+	fileContent, _, _, err := m.AdminGitHubClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get eMASS inventory: %v", err)
+	}
+
+	content, err := fileContent.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode eMASS inventory: %v", err)
+	}
+
+	return ParseEMASSInventory(path, content)
+}
+
+func (m *Manager) GetEMASSConfig(owner, repo, ref string) (*EMASS, error) {
+	content, found, err := m.GetFileContents(owner, repo, ".github/emass.json", ref)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	var emass EMASS
+	err = json.Unmarshal([]byte(content), &emass)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal emass.json: %v", err)
 	}
 
-	return fileContent.GetSHA(), nil
+	return &emass, nil
 }
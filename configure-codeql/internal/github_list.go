@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/department-of-veterans-affairs/codeql-tools/utils"
 	"github.com/google/go-github/v52/github"
@@ -32,10 +33,223 @@ func (m *Manager) ListRepos() ([]*github.Repository, error) {
 	return repos, nil
 }
 
+func (m *Manager) ListEnablementBranches(owner, repo string) ([]string, error) {
+	opts := &github.ReferenceListOptions{
+		Ref: fmt.Sprintf("heads/%s", SourceBranchName),
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var branches []string
+	for {
+		refs, resp, err := m.AdminGitHubClient.Git.ListMatchingRefs(m.Context, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list refs: %v", err)
+		}
+
+		for _, ref := range refs {
+			branches = append(branches, strings.TrimPrefix(ref.GetRef(), "refs/heads/"))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return branches, nil
+}
+
+func (m *Manager) ListEnablementPullRequests(owner, repo, state string) ([]*github.PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State:     state,
+		Sort:      "updated",
+		Direction: "desc",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var pullRequests []*github.PullRequest
+	for {
+		results, resp, err := m.ConfigureCodeQLInstallationClient.PullRequests.List(m.Context, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %v", err)
+		}
+
+		for _, pullRequest := range results {
+			if IsEnablementPullRequest(pullRequest) {
+				pullRequests = append(pullRequests, pullRequest)
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return pullRequests, nil
+}
+
+func (m *Manager) ListOISAnalysisCategories(owner, repo, branch string, since time.Time) ([]string, error) {
+	opts := &github.AnalysesListOptions{
+		Ref: github.String(fmt.Sprintf("refs/heads/%s", branch)),
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var categories []string
+	for {
+		analyses, resp, err := m.AdminGitHubClient.CodeScanning.ListAnalysesForRepo(m.Context, owner, repo, opts)
+		if err != nil {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to list code scanning analyses: %v", err)
+		}
+
+		done := false
+		for _, analysis := range analyses {
+			if analysis.GetCreatedAt().Before(since) {
+				done = true
+				continue
+			}
+			category := analysis.GetCategory()
+			if strings.HasPrefix(category, "ois-") && !Contains(categories, category) {
+				categories = append(categories, category)
+			}
+		}
+
+		if done || resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return categories, nil
+}
+
+func (m *Manager) ListPullRequestComments(owner, repo string, number int) ([]*github.IssueComment, error) {
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var comments []*github.IssueComment
+	for {
+		results, resp, err := m.ConfigureCodeQLInstallationClient.Issues.ListComments(m.Context, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments: %v", err)
+		}
+		comments = append(comments, results...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return comments, nil
+}
+
+func (m *Manager) ListPullRequestFiles(owner, repo string, number int) ([]*github.CommitFile, error) {
+	opts := &github.ListOptions{
+		PerPage: 100,
+	}
+
+	var files []*github.CommitFile
+	for {
+		results, resp, err := m.ConfigureCodeQLInstallationClient.PullRequests.ListFiles(m.Context, owner, repo, number, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull request files: %v", err)
+		}
+		files = append(files, results...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return files, nil
+}
+
+func (m *Manager) ListRepositoryAdmins(owner, repo string) ([]string, error) {
+	opts := &github.ListCollaboratorsOptions{
+		Affiliation: "direct",
+		Permission:  "admin",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var admins []string
+	for {
+		collaborators, resp, err := m.AdminGitHubClient.Repositories.ListCollaborators(m.Context, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list collaborators: %v", err)
+		}
+		for _, collaborator := range collaborators {
+			if collaborator.GetType() == "User" {
+				admins = append(admins, collaborator.GetLogin())
+			}
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return admins, nil
+}
+
+func (m *Manager) ListWorkflowFiles(owner, repo, ref string) ([]string, error) {
+	_, contents, resp, err := m.ConfigureCodeQLInstallationClient.Repositories.GetContents(m.Context, owner, repo, WorkflowsDirectory, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("failed to list workflows: %w", err)
+	}
+
+	var paths []string
+	for _, content := range contents {
+		if content.GetType() == "file" && IsWorkflowFile(content.GetName()) {
+			paths = append(paths, content.GetPath())
+		}
+	}
+
+	return paths, nil
+}
+
+func (m *Manager) ListRepositoryTree(owner, repo, sha string) ([]string, bool, error) {
+	tree, _, err := m.ConfigureCodeQLInstallationClient.Git.GetTree(m.Context, owner, repo, sha, true)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get tree: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range tree.Entries {
+		if entry.GetType() == "blob" {
+			paths = append(paths, entry.GetPath())
+		}
+	}
+
+	return paths, tree.GetTruncated(), nil
+}
+
 func (m *Manager) ListSupportedLanguages(org, repo string) ([]string, error) {
 	languages, resp, err := m.ConfigureCodeQLInstallationClient.Repositories.ListLanguages(m.Context, org, repo)
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return nil, fmt.Errorf("failed to list languages, unknown repository: %w", err)
 		}
 
@@ -64,7 +278,7 @@ func (m *Manager) ListVerifyScansInstalledRepos() ([]string, error) {
 	for {
 		results, resp, err := m.VerifyScansInstallationClient.Apps.ListRepos(m.Context, opts)
 		if err != nil {
-			if resp.StatusCode == http.StatusNotFound {
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
 				return nil, fmt.Errorf("failed to list installations, unknown organization: %w", err)
 			}
 			return nil, fmt.Errorf("failed to list installations: %w", err)
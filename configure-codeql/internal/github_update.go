@@ -1,23 +1,113 @@
 package internal
 
 import (
-	"encoding/base64"
 	"fmt"
+	"net/http"
 
 	"github.com/google/go-github/v52/github"
 )
 
-func (m *Manager) UpdateFile(owner, repo, branch, sha, path, message, content string) error {
-	content = base64.StdEncoding.EncodeToString([]byte(content))
-	opts := &github.RepositoryContentFileOptions{
-		Message: github.String(message),
-		Content: []byte(content),
-		Branch:  github.String(branch),
-		SHA:     github.String(sha),
+func (m *Manager) UpdateRef(owner, repo, branch, sha string, force bool) error {
+	ref := &github.Reference{
+		Ref: github.String(fmt.Sprintf("refs/heads/%s", branch)),
+		Object: &github.GitObject{
+			SHA: github.String(sha),
+		},
 	}
-	_, _, err := m.AdminGitHubClient.Repositories.UpdateFile(m.Context, owner, repo, path, opts)
+	_, _, err := m.AdminGitHubClient.Git.UpdateRef(m.Context, owner, repo, ref, force)
 	if err != nil {
-		return fmt.Errorf("failed to update file: %v", err)
+		return fmt.Errorf("failed to update ref: %v", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) UpdatePullRequest(owner, repo string, number int, title, body string) error {
+	_, _, err := m.ConfigureCodeQLInstallationClient.PullRequests.Edit(m.Context, owner, repo, number, &github.PullRequest{
+		Title: github.String(title),
+		Body:  github.String(body),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update pull request: %v", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) ClosePullRequest(owner, repo string, number int) error {
+	_, _, err := m.ConfigureCodeQLInstallationClient.PullRequests.Edit(m.Context, owner, repo, number, &github.PullRequest{
+		State: github.String("closed"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to close pull request: %v", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) DisableDefaultCodeScanning(org, repo string) error {
+	url := fmt.Sprintf("/repos/%s/%s/code-scanning/default-setup", org, repo)
+	req, err := m.AdminGitHubClient.NewRequest(http.MethodPatch, url, &DefaultCodeScanning{
+		State: "not-configured",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := m.AdminGitHubClient.Do(m.Context, req, nil)
+	if err != nil {
+		return fmt.Errorf("failed to make request: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (m *Manager) UpdateSecurityAndAnalysis(owner, repo string, securityAndAnalysis *github.SecurityAndAnalysis) error {
+	_, _, err := m.AdminGitHubClient.Repositories.Edit(m.Context, owner, repo, &github.Repository{
+		SecurityAndAnalysis: securityAndAnalysis,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update security and analysis settings: %v", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) EnableVulnerabilityAlerts(owner, repo string) error {
+	_, err := m.AdminGitHubClient.Repositories.EnableVulnerabilityAlerts(m.Context, owner, repo)
+	if err != nil {
+		return fmt.Errorf("failed to enable vulnerability alerts: %v", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) DisableVulnerabilityAlerts(owner, repo string) error {
+	_, err := m.AdminGitHubClient.Repositories.DisableVulnerabilityAlerts(m.Context, owner, repo)
+	if err != nil {
+		return fmt.Errorf("failed to disable vulnerability alerts: %v", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) EnableAutomatedSecurityFixes(owner, repo string) error {
+	_, err := m.AdminGitHubClient.Repositories.EnableAutomatedSecurityFixes(m.Context, owner, repo)
+	if err != nil {
+		return fmt.Errorf("failed to enable automated security fixes: %v", err)
+	}
+
+	return nil
+}
+
+func (m *Manager) DisableAutomatedSecurityFixes(owner, repo string) error {
+	_, err := m.AdminGitHubClient.Repositories.DisableAutomatedSecurityFixes(m.Context, owner, repo)
+	if err != nil {
+		return fmt.Errorf("failed to disable automated security fixes: %v", err)
 	}
 
 	return nil
@@ -8,22 +8,61 @@ import (
 	"github.com/google/go-github/v52/github"
 )
 
-func (m *Manager) CodeScanningEnabled(org, repo string) (bool, string, error) {
-	alerts, resp, err := m.AdminGitHubClient.CodeScanning.ListAnalysesForRepo(m.Context, org, repo, &github.AnalysesListOptions{
+func (m *Manager) CodeScanningEnabled(org, repo string) (bool, []string, error) {
+	analyses, resp, err := m.AdminGitHubClient.CodeScanning.ListAnalysesForRepo(m.Context, org, repo, &github.AnalysesListOptions{
 		ListOptions: github.ListOptions{
-			PerPage: 1,
+			PerPage: 100,
 		},
 	})
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
-			return false, "", nil
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil, nil
 		}
 
-		return false, "", fmt.Errorf("failed to list alerts: %w", err)
+		return false, nil, fmt.Errorf("failed to list analyses: %w", err)
+	}
+	if len(analyses) == 0 {
+		return false, nil, nil
+	}
+
+	var workflows []string
+	for _, analysis := range analyses {
+		workflow := strings.Split(analysis.GetAnalysisKey(), ":")[0]
+		if !Contains(workflows, workflow) {
+			workflows = append(workflows, workflow)
+		}
+	}
+
+	return true, workflows, nil
+}
+
+func (m *Manager) AutomatedSecurityFixesEnabled(org, repo string) (bool, error) {
+	url := fmt.Sprintf("/repos/%s/%s/automated-security-fixes", org, repo)
+	req, err := m.AdminGitHubClient.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
 	}
-	workflow := strings.Split(alerts[0].GetAnalysisKey(), ":")[0]
 
-	return true, workflow, nil
+	var result AutomatedSecurityFixes
+	resp, err := m.AdminGitHubClient.Do(m.Context, req, &result)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to make request: %w", err)
+	}
+
+	return result.Enabled, nil
+}
+
+func (m *Manager) VulnerabilityAlertsEnabled(org, repo string) (bool, error) {
+	enabled, _, err := m.AdminGitHubClient.Repositories.GetVulnerabilityAlerts(m.Context, org, repo)
+	if err != nil {
+		return false, fmt.Errorf("failed to get vulnerability alerts: %w", err)
+	}
+
+	return enabled, nil
 }
 
 func (m *Manager) DefaultCodeScanningEnabled(org, repo string) (bool, error) {
@@ -49,7 +88,22 @@ func (m *Manager) DefaultCodeScanningEnabled(org, repo string) (bool, error) {
 func (m *Manager) FileExists(owner, repo, path string) (bool, error) {
 	_, _, resp, err := m.ConfigureCodeQLInstallationClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+
+		return false, fmt.Errorf("failed to get file: %v", err)
+	}
+
+	return true, nil
+}
+
+func (m *Manager) FileExistsOnRef(owner, repo, path, ref string) (bool, error) {
+	_, _, resp, err := m.ConfigureCodeQLInstallationClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return false, nil
 		}
 
@@ -72,7 +126,7 @@ func (m *Manager) RefExists(owner, repo, branch string) (bool, error) {
 	ref := fmt.Sprintf("heads/%s", branch)
 	_, resp, err := m.AdminGitHubClient.Git.GetRef(m.Context, owner, repo, ref)
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return false, nil
 		}
 
@@ -82,30 +136,24 @@ func (m *Manager) RefExists(owner, repo, branch string) (bool, error) {
 	return true, nil
 }
 
-func (m *Manager) ReusableWorkflowInUse(org, repo, branch, path string) (bool, error) {
-	results, _, resp, err := m.VerifyScansInstallationClient.Repositories.GetContents(m.Context, org, repo, path, &github.RepositoryContentGetOptions{
-		Ref: branch,
-	})
+func (m *Manager) RepositoryEmpty(owner, repo, branch string) (bool, error) {
+	ref := fmt.Sprintf("heads/%s", branch)
+	_, resp, err := m.AdminGitHubClient.Git.GetRef(m.Context, owner, repo, ref)
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
-			return false, nil
+		if resp != nil && (resp.StatusCode == http.StatusConflict || resp.StatusCode == http.StatusNotFound) {
+			return true, nil
 		}
 
-		return false, fmt.Errorf("failed to get contents: %w", err)
-	}
-
-	content, err := results.GetContent()
-	if err != nil {
-		return false, fmt.Errorf("failed to get content: %w", err)
+		return false, fmt.Errorf("failed to get ref: %v", err)
 	}
 
-	return strings.Contains(strings.ToLower(content), SourceRepo), nil
+	return false, nil
 }
 
 func (m *Manager) VerifyScansAppInstalled(owner, repo string) (bool, error) {
 	_, resp, err := m.VerifyScansGithubClient.Apps.FindRepositoryInstallation(m.Context, owner, repo)
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return false, nil
 		}
 
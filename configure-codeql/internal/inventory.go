@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+const (
+	InventoryColumnRepository       = "repository"
+	InventoryColumnSystemID         = "system_id"
+	InventoryColumnSystemName       = "system_name"
+	InventoryColumnSystemOwnerName  = "system_owner_name"
+	InventoryColumnSystemOwnerEmail = "system_owner_email"
+)
+
+type EMASSInventory struct {
+	entries map[string]*InventoryEntry
+}
+
+type InventoryEntry struct {
+	Repository       string `json:"repository"`
+	SystemID         int64  `json:"system_id"`
+	SystemName       string `json:"system_name"`
+	SystemOwnerName  string `json:"system_owner_name"`
+	SystemOwnerEmail string `json:"system_owner_email"`
+}
+
+func ParseEMASSInventory(inventoryPath, content string) (*EMASSInventory, error) {
+	var entries []*InventoryEntry
+	var err error
+	if strings.ToLower(path.Ext(inventoryPath)) == ".json" {
+		err = json.Unmarshal([]byte(content), &entries)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal inventory: %w", err)
+		}
+	} else {
+		entries, err = parseInventoryCSV(content)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	inventory := &EMASSInventory{
+		entries: map[string]*InventoryEntry{},
+	}
+	for _, entry := range entries {
+		if entry.Repository == "" {
+			return nil, fmt.Errorf("inventory entry for system %d is missing a repository", entry.SystemID)
+		}
+		key := strings.ToLower(strings.TrimSpace(entry.Repository))
+		if existing, ok := inventory.entries[key]; ok {
+			return nil, fmt.Errorf("inventory lists repository %s more than once, for systems %d and %d", entry.Repository, existing.SystemID, entry.SystemID)
+		}
+		inventory.entries[key] = entry
+	}
+
+	return inventory, nil
+}
+
+func parseInventoryCSV(content string) ([]*InventoryEntry, error) {
+	reader := csv.NewReader(strings.NewReader(content))
+	reader.Comment = '#'
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read inventory: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := map[string]int{}
+	for i, column := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, column := range []string{InventoryColumnRepository, InventoryColumnSystemID, InventoryColumnSystemName, InventoryColumnSystemOwnerName, InventoryColumnSystemOwnerEmail} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("inventory is missing the %s column", column)
+		}
+	}
+
+	var entries []*InventoryEntry
+	for line, record := range records[1:] {
+		systemID, err := strconv.ParseInt(strings.TrimSpace(record[columns[InventoryColumnSystemID]]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse system ID on line %d: %w", line+2, err)
+		}
+		entries = append(entries, &InventoryEntry{
+			Repository:       strings.TrimSpace(record[columns[InventoryColumnRepository]]),
+			SystemID:         systemID,
+			SystemName:       strings.TrimSpace(record[columns[InventoryColumnSystemName]]),
+			SystemOwnerName:  strings.TrimSpace(record[columns[InventoryColumnSystemOwnerName]]),
+			SystemOwnerEmail: strings.TrimSpace(record[columns[InventoryColumnSystemOwnerEmail]]),
+		})
+	}
+
+	return entries, nil
+}
+
+func (i *EMASSInventory) Lookup(org, repo string) *InventoryEntry {
+	if i == nil {
+		return nil
+	}
+
+	if entry, ok := i.entries[strings.ToLower(fmt.Sprintf("%s/%s", org, repo))]; ok {
+		return entry
+	}
+
+	return i.entries[strings.ToLower(repo)]
+}
+
+func (i *EMASSInventory) Len() int {
+	if i == nil {
+		return 0
+	}
+
+	return len(i.entries)
+}
@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseEMASSInventory(t *testing.T) {
+	tests := []struct {
+		name    string
+		path    string
+		content string
+		entries map[string]*InventoryEntry
+		err     string
+	}{
+		{
+			name: "csv inventory",
+			path: "inventory.csv",
+			content: `# eMASS inventory
+repository, system_id, system_name, system_owner_name, system_owner_email
+org/Payments-API, 1234, Payments, Jane Doe, jane.doe@example.com
+claims-api, 5678, Claims, John Doe, john.doe@example.com
+`,
+			entries: map[string]*InventoryEntry{
+				"org/payments-api": {Repository: "org/Payments-API", SystemID: 1234, SystemName: "Payments", SystemOwnerName: "Jane Doe", SystemOwnerEmail: "jane.doe@example.com"},
+				"claims-api":       {Repository: "claims-api", SystemID: 5678, SystemName: "Claims", SystemOwnerName: "John Doe", SystemOwnerEmail: "john.doe@example.com"},
+			},
+		},
+		{
+			name:    "json inventory",
+			path:    "inventory.JSON",
+			content: `[{"repository": "org/payments-api", "system_id": 1234, "system_name": "Payments", "system_owner_name": "Jane Doe", "system_owner_email": "jane.doe@example.com"}]`,
+			entries: map[string]*InventoryEntry{
+				"org/payments-api": {Repository: "org/payments-api", SystemID: 1234, SystemName: "Payments", SystemOwnerName: "Jane Doe", SystemOwnerEmail: "jane.doe@example.com"},
+			},
+		},
+		{
+			name: "duplicate system IDs",
+			path: "inventory.csv",
+			content: `repository,system_id,system_name,system_owner_name,system_owner_email
+payments-api,1234,Payments,Jane Doe,jane.doe@example.com
+payments-ui,1234,Payments,Jane Doe,jane.doe@example.com
+`,
+			entries: map[string]*InventoryEntry{
+				"payments-api": {Repository: "payments-api", SystemID: 1234, SystemName: "Payments", SystemOwnerName: "Jane Doe", SystemOwnerEmail: "jane.doe@example.com"},
+				"payments-ui":  {Repository: "payments-ui", SystemID: 1234, SystemName: "Payments", SystemOwnerName: "Jane Doe", SystemOwnerEmail: "jane.doe@example.com"},
+			},
+		},
+		{
+			name: "duplicate repositories",
+			path: "inventory.csv",
+			content: `repository,system_id,system_name,system_owner_name,system_owner_email
+payments-api,1234,Payments,Jane Doe,jane.doe@example.com
+Payments-API,5678,Claims,John Doe,john.doe@example.com
+`,
+			err: "inventory lists repository Payments-API more than once, for systems 1234 and 5678",
+		},
+		{
+			name:    "empty csv inventory",
+			path:    "inventory.csv",
+			content: "",
+			entries: map[string]*InventoryEntry{},
+		},
+		{
+			name:    "header only csv inventory",
+			path:    "inventory.csv",
+			content: "repository,system_id,system_name,system_owner_name,system_owner_email\n",
+			entries: map[string]*InventoryEntry{},
+		},
+		{
+			name:    "empty json inventory",
+			path:    "inventory.json",
+			content: "",
+			err:     "failed to unmarshal inventory",
+		},
+		{
+			name: "row with missing fields",
+			path: "inventory.csv",
+			content: `repository,system_id,system_name,system_owner_name,system_owner_email
+payments-api,1234,Payments
+`,
+			err: "failed to read inventory",
+		},
+		{
+			name: "row with invalid system ID",
+			path: "inventory.csv",
+			content: `repository,system_id,system_name,system_owner_name,system_owner_email
+payments-api,1234,Payments,Jane Doe,jane.doe@example.com
+claims-api,unknown,Claims,John Doe,john.doe@example.com
+`,
+			err: "failed to parse system ID on line 3",
+		},
+		{
+			name: "row without repository",
+			path: "inventory.csv",
+			content: `repository,system_id,system_name,system_owner_name,system_owner_email
+,1234,Payments,Jane Doe,jane.doe@example.com
+`,
+			err: "inventory entry for system 1234 is missing a repository",
+		},
+		{
+			name: "missing column",
+			path: "inventory.csv",
+			content: `repository,system_id,system_name,system_owner_name
+payments-api,1234,Payments,Jane Doe
+`,
+			err: "inventory is missing the system_owner_email column",
+		},
+		{
+			name:    "malformed json",
+			path:    "inventory.json",
+			content: `[{"repository": "payments-api", "system_id": "1234"}]`,
+			err:     "failed to unmarshal inventory",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			inventory, err := ParseEMASSInventory(test.path, test.content)
+			if test.err != "" {
+				if err == nil {
+					t.Fatalf("got no error, want %q", test.err)
+				}
+				if !strings.Contains(err.Error(), test.err) {
+					t.Errorf("got error %q, want %q", err, test.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(inventory.entries, test.entries) {
+				t.Errorf("got entries %v, want %v", inventory.entries, test.entries)
+			}
+		})
+	}
+}
+
+func TestEMASSInventoryLookup(t *testing.T) {
+	inventory, err := ParseEMASSInventory("inventory.csv", `repository,system_id,system_name,system_owner_name,system_owner_email
+org/payments-api,1234,Payments,Jane Doe,jane.doe@example.com
+claims-api,5678,Claims,John Doe,john.doe@example.com
+`)
+	if err != nil {
+		t.Fatalf("failed to parse inventory: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		org      string
+		repo     string
+		systemID int64
+	}{
+		{name: "org prefixed", org: "Org", repo: "Payments-API", systemID: 1234},
+		{name: "other org", org: "other", repo: "payments-api"},
+		{name: "repository only", org: "any", repo: "claims-api", systemID: 5678},
+		{name: "missing", org: "org", repo: "forms-service"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			entry := inventory.Lookup(test.org, test.repo)
+			if test.systemID == 0 {
+				if entry != nil {
+					t.Errorf("got entry %v, want none", entry)
+				}
+				return
+			}
+			if entry == nil || entry.SystemID != test.systemID {
+				t.Errorf("got entry %v, want system %d", entry, test.systemID)
+			}
+		})
+	}
+}
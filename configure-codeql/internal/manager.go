@@ -3,8 +3,13 @@ package internal
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
 	"github.com/google/go-github/v52/github"
 	log "github.com/sirupsen/logrus"
 )
@@ -17,16 +22,111 @@ type Manager struct {
 	VerifyScansGithubClient           *github.Client
 	VerifyScansInstallationClient     *github.Client
 
-	Config       *Input
-	Logger       *log.Entry
-	GlobalLogger *log.Logger
+	Config              *Input
+	GlobalLogger        *log.Logger
+	Policy              *RolloutPolicy
+	EMASSInventory      *EMASSInventory
+	Exemptions          *utils.ExemptionChecker
+	Scheduler           *Scheduler
+	PullRequestTemplate *template.Template
 
 	VerifiedScansAppInstalledRepos []string
+	Plans                          []*RepositoryPlan
+	Statuses                       []*RepositoryStatus
+
+	plansMutex     sync.Mutex
+	statusesMutex  sync.Mutex
+	installedMutex sync.Mutex
+}
+
+func NewManager(config *Input, logger *log.Logger, scheduler *Scheduler) (*Manager, error) {
+	logger.Infof("Validating pull request body template")
+	pullRequestTemplate, err := ParsePullRequestTemplate(config.PullRequestBody, config.EMASSDocumentationURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pull_request_body input: %v", err)
+	}
+	logger.Debugf("Pull request body template validated")
+
+	logger.Infof("Creating admin GitHub client")
+	adminClient := utils.NewGitHubClient(config.AdminToken)
+
+	logger.Infof("Creating Configure CodeQL GitHub Installation client")
+	configureCodeQLInstallationClient, err := utils.NewGitHubInstallationClient(config.ConfigureCodeQLAppID, config.ConfigureCodeQLInstallationID, config.ConfigureCodeQLPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create EMASS GitHub App client: %v", err)
+	}
+
+	logger.Infof("Creating Verify Scans GitHub App client")
+	verifyScansClient, err := utils.NewGitHubAppClient(config.VerifyScansAppID, config.VerifyScansPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Verify Scans GitHub App client: %v", err)
+	}
+	logger.Debugf("Verify Scans GitHub App client created")
+
+	logger.Infof("Creating Verify Scans GitHub App Installation client")
+	verifyScansInstallationClient, err := utils.NewGitHubInstallationClient(config.VerifyScansAppID, config.VerifyScansInstallationID, config.VerifyScansPrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Verify Scans GitHub App client: %v", err)
+	}
+	logger.Debugf("Verify Scans GitHub App client created")
+
+	m := &Manager{
+		AdminGitHubClient:                 adminClient,
+		ConfigureCodeQLInstallationClient: configureCodeQLInstallationClient,
+		VerifyScansGithubClient:           verifyScansClient,
+		VerifyScansInstallationClient:     verifyScansInstallationClient,
+
+		Config:              config,
+		Context:             context.Background(),
+		GlobalLogger:        logger,
+		PullRequestTemplate: pullRequestTemplate,
+		Scheduler:           scheduler,
+	}
+
+	m.Policy = &RolloutPolicy{}
+	if config.PolicyRepo != "" {
+		logger.Infof("Retrieving rollout policy from %s/%s", config.PolicyRepo, config.PolicyPath)
+		policy, err := m.GetRolloutPolicy(config.Org, config.PolicyRepo, config.PolicyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve rollout policy: %v", err)
+		}
+		m.Policy = policy
+		logger.Debugf("Retrieved rollout policy with %d policies", len(policy.Policies))
+	}
+
+	if config.EMASSInventoryRepo != "" {
+		logger.Infof("Retrieving eMASS inventory from %s/%s", config.EMASSInventoryRepo, config.EMASSInventoryPath)
+		inventory, err := m.GetEMASSInventory(config.Org, config.EMASSInventoryRepo, config.EMASSInventoryPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve eMASS inventory: %v", err)
+		}
+		m.EMASSInventory = inventory
+		logger.Debugf("Retrieved %d eMASS inventory entries", inventory.Len())
+	}
+
+	if config.ExemptionProperty != "" {
+		logger.Infof("Retrieving allowed values of custom property %s", config.ExemptionProperty)
+		exemptions, err := utils.NewExemptionChecker(m.Context, m.AdminGitHubClient, config.Org, config.ExemptionProperty)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve exemption property: %v", err)
+		}
+		m.Exemptions = exemptions
+		logger.Debugf("Retrieved %d approved exemptions", len(exemptions.AllowedValues))
+	}
+
+	logger.Infof("Querying verify-scans app for all installed repos")
+	installedRepos, err := m.ListVerifyScansInstalledRepos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed repos: %v", err)
+	}
+	m.VerifiedScansAppInstalledRepos = installedRepos
+	logger.Debugf("found %d installed repos", len(installedRepos))
+
+	return m, nil
 }
 
 func (m *Manager) ProcessRepository(repo *github.Repository) {
 	logger := m.GlobalLogger.WithField("repo", repo.GetName())
-	m.Logger = logger
 
 	org := repo.GetOwner().GetLogin()
 	name := repo.GetName()
@@ -37,6 +137,24 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		return
 	}
 
+	plan := NewRepositoryPlan(name, defaultBranch)
+	if m.Config.PlanMode {
+		defer m.addPlan(plan)
+	}
+
+	logger.Infof("Classifying repository type")
+	repositoryType, err := m.SkippedRepositoryType(repo)
+	if err != nil {
+		logger.Errorf("failed to classify repository type, skipping repo: %v", err)
+		return
+	}
+	if repositoryType != "" {
+		plan.Skip(fmt.Sprintf("skipped-%s", repositoryType))
+		logger.WithField("event", RepositoryTypeEvents[repositoryType]).Infof("Repository is %s, skipping", repositoryType)
+		return
+	}
+	logger.Debugf("Repository type classified")
+
 	logger.Info("Checking if repository is ignored")
 	repoIgnored, err := m.FileExists(org, name, ".github/.emass-repo-ignore")
 	if err != nil {
@@ -44,179 +162,533 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		return
 	}
 	if repoIgnored {
-		logger.WithField("event", "skipped-ignored").Infof("Found .emass-repo-ignore file, skipping repository")
+		plan.Skip("skipped-ignored")
+		logger.WithField("event", logging.EventSkippedIgnored).Infof("Found .emass-repo-ignore file, skipping repository")
 		return
 	}
 
+	if m.Exemptions != nil {
+		logger.Infof("Checking if repository has an approved exemption")
+		exempt, err := m.Exemptions.Exempt(org, name, logger)
+		if err != nil {
+			logger.Errorf("failed to check if repository has an approved exemption, skipping repo: %v", err)
+			return
+		}
+		if exempt {
+			plan.Skip("skipped-exempt")
+			return
+		}
+		logger.Debugf("Repository does not have an approved exemption")
+	}
+
 	logger.Infof("Checking if repository is already configured")
-	if Contains(m.VerifiedScansAppInstalledRepos, name) {
-		logger.WithField("event", "skipped-already-configured").Infof("Skipping repository as it is has already been configured via the Configure CodeQL GitHub App Pull Request")
-		return
+	if m.VerifyScansInstalled(name) {
+		var closedPullRequest *github.PullRequest
+		if !repo.GetArchived() {
+			logger.Infof("Retrieving all '%s' pull requests", PullRequestTitle)
+			pullRequests, err := m.ListEnablementPullRequests(org, name, "all")
+			if err != nil {
+				logger.Errorf("failed to retrieve pull requests, skipping repo: %v", err)
+				return
+			}
+			var openPullRequests []*github.PullRequest
+			for _, pullRequest := range pullRequests {
+				if pullRequest.GetState() == "open" {
+					openPullRequests = append(openPullRequests, pullRequest)
+				}
+			}
+			if pullRequest, _ := SelectEnablementPullRequest(openPullRequests); pullRequest != nil {
+				_, err = m.RebaseConflictingPullRequest(repo, pullRequest, logger, plan)
+				if err != nil {
+					logger.Errorf("failed to resolve conflicts of pull request #%d, skipping repo: %v", pullRequest.GetNumber(), err)
+					return
+				}
+			} else if latest := LatestPullRequest(pullRequests); latest != nil && latest.MergedAt == nil {
+				retry, err := m.ClosedPullRequestRetryable(repo, logger)
+				if err != nil {
+					logger.Errorf("failed to analyze workflows, skipping repo: %v", err)
+					return
+				}
+				if retry {
+					closedPullRequest = latest
+				}
+			}
+		}
+		if closedPullRequest == nil {
+			plan.Skip("skipped-already-configured")
+			logger.WithField("event", logging.EventSkippedAlreadyConfigured).Infof("Skipping repository as it is has already been configured via the Configure CodeQL GitHub App Pull Request")
+			return
+		}
+		logger.WithFields(log.Fields{
+			"event":                   logging.EventRetryingClosedPullRequest,
+			logging.DetailPullRequest: closedPullRequest.GetNumber(),
+		}).Infof("Pull request #%d was closed without merging, configuring repository again", closedPullRequest.GetNumber())
 	}
 
 	logger.Infof("Checking if repository is archived")
 	if repo.GetArchived() {
-		logger.WithField("event", "skipped-archived").Infof("Repository is archived, skipping")
+		plan.Skip("skipped-archived")
+		logger.WithField("event", logging.EventSkippedArchived).Infof("Repository is archived, skipping")
+		return
+	}
+
+	logger.Infof("Retrieving supported languages")
+	languages, err := m.ListSupportedLanguages(repo.Owner.GetLogin(), repo.GetName())
+	if err != nil {
+		logger.Errorf("failed to retrieve supported languages, skipping repo: %v", err)
+		return
+	}
+	logger.Debugf("Retrieved %d supported languages", len(languages))
+	plan.Languages = languages
+
+	logger.Infof("Resolving rollout policy")
+	policy, err := m.ResolvePolicy(repo, languages)
+	if err != nil {
+		logger.Errorf("failed to resolve rollout policy, skipping repo: %v", err)
+		return
+	}
+	plan.Policy = policy.Name
+	if policy.Exclude {
+		plan.Skip("skipped-excluded-by-policy")
+		logger.WithField("event", logging.EventSkippedExcludedByPolicy).Infof("Repository is excluded from rollout by policy '%s', skipping", policy.Name)
 		return
 	}
+	logger.Debugf("Resolved rollout policy '%s'", policy.Name)
 
 	logger.Infof("Checking if repository has Code Scanning enabled")
-	enabled, workflowPath, err := m.CodeScanningEnabled(m.Config.Org, name)
+	enabled, _, err := m.CodeScanningEnabled(m.Config.Org, name)
 	if err != nil {
 		logger.Errorf("failed to check if repository has Code Scanning enabled, skipping repo: %v", err)
 		return
 	}
+	defaultScanningEnabled := false
 	if enabled {
 		logger.Infof("Code scanning enabled, validating repository in not using default code scanning")
-		defaultScanningEnabled, err := m.DefaultCodeScanningEnabled(m.Config.Org, name)
+		defaultScanningEnabled, err = m.DefaultCodeScanningEnabled(m.Config.Org, name)
 		if err != nil {
 			logger.Errorf("failed to check if repository is using default code scanning, skipping repo: %v", err)
 			return
 		}
-		if defaultScanningEnabled {
-			logger.Infof("Default code scanning enabled, configuring repository")
-		} else {
-			logger.Infof("Default code scanning disabled, validating reusable workflow in use")
-			reusableWorkflowInUse, err := m.ReusableWorkflowInUse(m.Config.Org, name, repo.GetDefaultBranch(), workflowPath)
-			if err != nil {
-				logger.Errorf("Failed to check if reusable workflow is in use, skipping repo: %v", err)
-				return
-			}
-
-			if reusableWorkflowInUse {
-				logger.Infof("Reusable workflow in use, installing Verify Scans app")
-				err = m.InstallVerifyScansApp(repo.GetID())
-				if err != nil {
-					logger.Errorf("failed to install verify-scans app, skipping repo: %v", err)
-					return
-				}
-				logger.WithField("event", "repo-already-configured").Infof("Verify Scans app installed")
-				return
-			}
-			logger.Infof("Reusable workflow not in use, configuring repository")
-		}
 	}
 	logger.Debugf("Finished checking if repository has Code Scanning enabled")
 
-	logger.Infof("Retrieving supported languages")
-	languages, err := m.ListSupportedLanguages(repo.Owner.GetLogin(), repo.GetName())
+	logger.Infof("Analyzing workflows in '%s'", WorkflowsDirectory)
+	workflowAnalysis, err := m.AnalyzeWorkflows(org, name, defaultBranch)
 	if err != nil {
-		logger.Errorf("failed to retrieve supported languages, skipping repo: %v", err)
+		logger.Errorf("failed to analyze workflows, skipping repo: %v", err)
 		return
 	}
-	logger.Debugf("Retrieved %d supported languages", len(languages))
+	for _, workflowFile := range workflowAnalysis.Workflows {
+		logger.Debugf("Analyzed workflow %s: %s", workflowFile.Path, workflowFile.Summary())
+	}
+	directWorkflows := workflowAnalysis.DirectWorkflows()
+
+	if defaultScanningEnabled {
+		logger.Infof("Default code scanning enabled, configuring repository")
+	} else if len(workflowAnalysis.ReusableWorkflows()) > 0 && len(directWorkflows) == 0 {
+		logger.Infof("Reusable workflow in use, installing Verify Scans app")
+		if m.Config.PlanMode {
+			plan.Status = PlanStatusInstall
+			plan.AddIntent(IntentInstallVerifyScansApp, name, nil)
+			logger.WithField("event", logging.EventPlanned).Infof("Plan mode enabled, recorded Verify Scans app installation")
+			return
+		}
+		err = m.InstallVerifyScansApp(repo.GetID())
+		if err != nil {
+			logger.Errorf("failed to install verify-scans app, skipping repo: %v", err)
+			return
+		}
+		logger.WithField("event", logging.EventRepoAlreadyConfigured).Infof("Verify Scans app installed")
+		return
+	} else if len(directWorkflows) > 0 {
+		logger.WithField("event", logging.EventConvertingCodeQLAction).Infof("Found %d workflows using '%s' directly, converting to the reusable workflow", len(directWorkflows), CodeQLActionPrefix)
+	} else {
+		logger.Infof("Reusable workflow not in use, configuring repository")
+	}
 
 	if len(languages) == 0 {
-		logger.WithField("event", "skipped-no-supported-languages").Infof("Skipping repository as it does not contain any supported languages")
+		plan.Skip("skipped-no-supported-languages")
+		logger.WithField("event", logging.EventSkippedNoSupportedLanguages).Infof("Skipping repository as it does not contain any supported languages")
 		return
 	}
 
-	logger.Infof("Generating CodeQL workflow for supported languages: [%s]", strings.Join(languages, ", "))
-	workflow, err := GenerateCodeQLWorkflow(languages, repo.GetDefaultBranch())
+	logger.Infof("Retrieving SHA for branch %s", defaultBranch)
+	sha, err := m.GetDefaultRefSHA(org, name, defaultBranch)
 	if err != nil {
-		logger.Errorf("failed to generate CodeQL workflow, skipping repo: %v", err)
+		logger.Errorf("failed to retrieve SHA for branch %s, skipping repo: %v", defaultBranch, err)
 		return
 	}
-	logger.Debugf("Generated CodeQL workflow")
+	logger.Debugf("Retrieved SHA %s for branch %s", sha, defaultBranch)
 
-	logger.Infof("Generating emass.json contents")
-	emassJSON, err := GenerateEMASSJSON()
+	logger.Infof("Retrieving existing '%s' pull requests", PullRequestTitle)
+	pullRequests, err := m.ListEnablementPullRequests(org, name, "open")
 	if err != nil {
-		logger.Errorf("failed to generate emass.json, skipping repo: %v", err)
+		logger.Errorf("failed to retrieve existing pull requests, skipping repo: %v", err)
 		return
 	}
-	logger.Debugf("Generated emass.json")
+	existingPullRequest, duplicatePullRequests := SelectEnablementPullRequest(pullRequests)
+	logger.Debugf("Retrieved %d existing pull requests", len(pullRequests))
 
-	logger.Infof("Retrieving SHA for branch %s", defaultBranch)
-	sha, err := m.GetDefaultRefSHA(org, name, defaultBranch)
+	var files []GeneratedFile
+	workflowChanges := map[string][]string{}
+	for _, target := range directWorkflows {
+		logger.Infof("Merging CodeQL workflow into existing file '%s'", target.Path)
+		content, changes, converted, err := MergeCodeQLWorkflow(target.Content, languages, defaultBranch, policy)
+		if err != nil {
+			logger.Errorf("failed to merge CodeQL workflow into '%s', skipping repo: %v", target.Path, err)
+			return
+		}
+		if len(changes) > 0 {
+			workflowChanges[target.Path] = changes
+		}
+		if !converted {
+			logger.Infof("No CodeQL steps converted in '%s', leaving file unchanged", target.Path)
+			continue
+		}
+		files = append(files, GeneratedFile{
+			Path:    target.Path,
+			Content: content,
+		})
+		logger.Debugf("Merged CodeQL workflow into '%s' with %d changes", target.Path, len(changes))
+	}
+
+	workflowReplaced := len(files) > 0
+	if !workflowReplaced {
+		workflowPath := DefaultWorkflowPath
+		if workflowAnalysis.Workflow(DefaultWorkflowPath) != nil {
+			workflowPath = FallbackWorkflowPath
+		}
+
+		var previousCrons []string
+		if existingPullRequest != nil {
+			logger.Infof("Retrieving schedule of '%s' on branch %s", workflowPath, existingPullRequest.GetHead().GetRef())
+			previous, found, err := m.GetFileContents(org, name, workflowPath, existingPullRequest.GetHead().GetRef())
+			if err != nil {
+				logger.Errorf("failed to retrieve '%s' from branch %s, skipping repo: %v", workflowPath, existingPullRequest.GetHead().GetRef(), err)
+				return
+			}
+			if found {
+				previousCrons = ClassifyWorkflow(workflowPath, previous).Crons
+			}
+		}
+
+		cron, kept := m.Scheduler.Assign(repo.GetID(), policy.ScheduleWindows, previousCrons)
+		plan.Schedule = cron
+		if kept {
+			logger.Debugf("Keeping existing schedule '%s'", cron)
+		} else {
+			logger.Debugf("Assigned schedule '%s'", cron)
+		}
+
+		logger.Infof("Generating CodeQL workflow for supported languages: [%s]", strings.Join(languages, ", "))
+		content, err := GenerateCodeQLWorkflow(languages, defaultBranch, cron, policy)
+		if err != nil {
+			logger.Errorf("failed to generate CodeQL workflow, skipping repo: %v", err)
+			return
+		}
+		files = append(files, GeneratedFile{
+			Path:    workflowPath,
+			Content: content,
+		})
+		logger.Debugf("Generated CodeQL workflow '%s'", workflowPath)
+	}
+
+	logger.Infof("Checking if '.github/emass.json' exists")
+	emassExists, err := m.FileExists(org, name, ".github/emass.json")
 	if err != nil {
-		logger.Errorf("failed to retrieve SHA for branch %s, skipping repo: %v", defaultBranch, err)
+		logger.Errorf("failed to check if emass.json exists, skipping repo: %v", err)
 		return
 	}
-	logger.Debugf("Retrieved SHA %s for branch %s", sha, defaultBranch)
+	if !emassExists && existingPullRequest != nil {
+		emassExists, err = m.FileExistsOnRef(org, name, ".github/emass.json", existingPullRequest.GetHead().GetSHA())
+		if err != nil {
+			logger.Errorf("failed to check if emass.json exists on branch %s, skipping repo: %v", existingPullRequest.GetHead().GetRef(), err)
+			return
+		}
+		if emassExists {
+			logger.Infof("emass.json exists on branch %s, preserving file", existingPullRequest.GetHead().GetRef())
+		}
+	}
+	var checklist []string
+	var emassEntry *InventoryEntry
+	if !emassExists {
+		logger.Infof("Looking up repository in eMASS inventory")
+		emassEntry = m.EMASSInventory.Lookup(org, name)
+		if emassEntry == nil {
+			checklist = append(checklist, "Replace the placeholder values in `.github/emass.json` with the eMASS System ID, System Name, and System Owner of this repository")
+			logger.WithField("event", logging.EventEMASSInventoryNotFound).Infof("Repository not found in eMASS inventory, using placeholder emass.json values")
+		} else {
+			logger.Debugf("Found repository in eMASS inventory with system ID %d", emassEntry.SystemID)
+		}
 
-	ghasBranch := SourceBranchName
-	logger.Infof("Checking if branch %s exists", ghasBranch)
-	branchExists, err := m.RefExists(org, name, ghasBranch)
+		logger.Infof("Generating emass.json contents")
+		emassJSON, err := GenerateEMASSJSON(emassEntry)
+		if err != nil {
+			logger.Errorf("failed to generate emass.json, skipping repo: %v", err)
+			return
+		}
+		logger.Debugf("Generated emass.json")
+
+		logger.Infof("emass.json does not exist, adding file to commit")
+		files = append(files, GeneratedFile{
+			Path:    ".github/emass.json",
+			Content: emassJSON,
+		})
+	} else {
+		logger.Infof("emass.json exists on branch %s, skipping file", defaultBranch)
+	}
+
+	logger.Infof("Checking if '%s' exists", CodeQLConfigPath)
+	codeqlConfigExists, err := m.FileExists(org, name, CodeQLConfigPath)
 	if err != nil {
-		logger.Errorf("failed to check if branch %s exists, skipping repo: %v", ghasBranch, err)
+		logger.Errorf("failed to check if %s exists, skipping repo: %v", CodeQLConfigPath, err)
 		return
 	}
-	if branchExists {
-		logger.Infof("Branch %s exists, appending random suffix", ghasBranch)
-		ghasBranch = fmt.Sprintf("%s-%s", ghasBranch, GenerateRandomSuffix(5))
+	var buildSteps []BuildStepProposal
+	if !codeqlConfigExists {
+		logger.Infof("Detecting build systems")
+		tree, truncated, err := m.ListRepositoryTree(org, name, sha)
+		if err != nil {
+			logger.Errorf("failed to list repository tree, skipping repo: %v", err)
+			return
+		}
+		if truncated {
+			logger.Warnf("Repository tree was truncated, build system detection may be incomplete")
+		}
+		buildSteps = DetectBuildSteps(tree, languages)
+		if len(buildSteps) > 0 {
+			codeqlConfig, err := GenerateCodeQLConfig(buildSteps)
+			if err != nil {
+				logger.Errorf("failed to generate %s, skipping repo: %v", CodeQLConfigPath, err)
+				return
+			}
+			files = append(files, GeneratedFile{
+				Path:    CodeQLConfigPath,
+				Content: codeqlConfig,
+			})
+			for _, proposal := range buildSteps {
+				if proposal.Confidence != ConfidenceHigh {
+					checklist = append(checklist, fmt.Sprintf("Verify the build steps proposed in `%s`", CodeQLConfigPath))
+					break
+				}
+			}
+		}
+		logger.Debugf("Proposed build steps for %d languages", len(buildSteps))
+	} else {
+		logger.Infof("%s exists on branch %s, skipping build system detection", CodeQLConfigPath, defaultBranch)
 	}
-	logger.Debugf("Branch name is %s", ghasBranch)
 
-	logger.Infof("Creating branch %s", ghasBranch)
-	err = m.CreateRef(org, name, ghasBranch, sha)
+	logger.Infof("Checking security features required by rollout policy '%s'", policy.Name)
+	securityFeatures, err := m.MissingSecurityFeatures(repo, policy, logger)
 	if err != nil {
-		logger.Errorf("failed to create branch %s, skipping repo: %v", ghasBranch, err)
+		logger.Errorf("failed to check security features, skipping repo: %v", err)
 		return
 	}
-	logger.Debugf("Created branch %s", ghasBranch)
+	logger.Debugf("Security features checked")
+
+	ghasBranch := SourceBranchName
+	if existingPullRequest != nil {
+		ghasBranch = existingPullRequest.GetHead().GetRef()
+	}
 
-	logger.Infof("Checking if '.github/workflows/codeql-analysis.yml' exists")
-	workflowExists, err := m.FileExists(org, name, ".github/workflows/codeql-analysis.yml")
+	logger.Infof("Generating pull request body with supported languages: [%s]", strings.Join(languages, ", "))
+	pullRequestData := PullRequestData{
+		Org:              org,
+		Repo:             name,
+		DefaultBranch:    defaultBranch,
+		Branch:           ghasBranch,
+		Languages:        languages,
+		WorkflowChanges:  workflowChanges,
+		EMASSMapped:      emassEntry != nil,
+		Checklist:        checklist,
+		BuildSteps:       buildSteps,
+		SecurityFeatures: securityFeatures,
+		Links: PullRequestLinks{
+			EMASSDocumentation: m.Config.EMASSDocumentationURL,
+		},
+	}
+	if emassEntry != nil {
+		pullRequestData.EMASS = EMASS{
+			SystemID:         emassEntry.SystemID,
+			SystemName:       emassEntry.SystemName,
+			SystemOwnerName:  emassEntry.SystemOwnerName,
+			SystemOwnerEmail: emassEntry.SystemOwnerEmail,
+		}
+	}
+	for _, file := range files {
+		pullRequestData.Files = append(pullRequestData.Files, file.Path)
+	}
+	pullRequestData.WorkflowReplaced = workflowReplaced
+	body, err := RenderPullRequestBody(m.PullRequestTemplate, pullRequestData)
 	if err != nil {
-		logger.Errorf("failed to check if workflow file exists, skipping repo: %v", err)
+		logger.Errorf("failed to generate pull request body, skipping repo: %v", err)
 		return
 	}
-	if !workflowExists {
-		logger.Infof("Workflow file does not exist, creating file")
-		err = m.CreateFile(org, name, ghasBranch, ".github/workflows/codeql-analysis.yml", "Create CodeQL workflow", workflow)
+	logger.Debugf("Pull request body: %s", body)
+
+	tx := m.NewTransaction(org, name, logger)
+	defer tx.Rollback()
+
+	parentSHA := sha
+	if existingPullRequest != nil {
+		parentSHA = existingPullRequest.GetHead().GetSHA()
+		logger.Infof("Found existing pull request #%d, reusing branch %s", existingPullRequest.GetNumber(), ghasBranch)
+
+		rebasedSHA, err := m.RebaseConflictingPullRequest(repo, existingPullRequest, logger, plan)
 		if err != nil {
-			logger.Errorf("failed to create workflow file, skipping repo: %v", err)
+			logger.Errorf("failed to resolve conflicts of pull request #%d, skipping repo: %v", existingPullRequest.GetNumber(), err)
 			return
 		}
-		logger.Debugf("Created workflow file")
+		if rebasedSHA != "" {
+			tx.RecordRefUpdate(ghasBranch, parentSHA)
+			parentSHA = rebasedSHA
+		}
 	} else {
-		logger.Infof("Workflow file exists, retrieving SHA for file")
-		workflowSHA, err := m.GetFileSHA(org, name, ".github/workflows/codeql-analysis.yml", ghasBranch)
+		logger.Infof("Checking if branch %s exists", ghasBranch)
+		branchExists, err := m.RefExists(org, name, ghasBranch)
 		if err != nil {
-			logger.Errorf("failed to retrieve SHA for workflow file, skipping repo: %v", err)
+			logger.Errorf("failed to check if branch %s exists, skipping repo: %v", ghasBranch, err)
 			return
 		}
-		logger.Debugf("Retrieved SHA %s for workflow file", sha)
+		if branchExists {
+			logger.Infof("Branch %s exists without an open pull request, deleting branch", ghasBranch)
+			if m.Config.PlanMode {
+				plan.AddIntent(IntentDeleteRef, ghasBranch, nil)
+			} else {
+				err = m.DeleteRef(org, name, ghasBranch)
+				if err != nil {
+					logger.Errorf("failed to delete branch %s, skipping repo: %v", ghasBranch, err)
+					return
+				}
+			}
+			logger.Debugf("Deleted branch %s", ghasBranch)
+		}
+	}
+	plan.Branch = ghasBranch
 
-		logger.Infof("Updating workflow file")
-		err = m.UpdateFile(org, name, ghasBranch, workflowSHA, ".github/workflows/codeql-analysis.yml", "Update CodeQL workflow", workflow)
+	if m.Config.PlanMode {
+		for _, file := range files {
+			exists, err := m.FileExistsOnRef(org, name, file.Path, parentSHA)
+			if err != nil {
+				logger.Errorf("failed to check if '%s' exists, skipping repo: %v", file.Path, err)
+				return
+			}
+			action := IntentCreateFile
+			if exists {
+				action = IntentUpdateFile
+			}
+			plan.AddIntent(action, file.Path, map[string]string{
+				"branch":  ghasBranch,
+				"content": file.Content,
+			})
+		}
+		plan.AddIntent(IntentCreateCommit, ghasBranch, map[string]string{
+			"parent":  parentSHA,
+			"message": m.Config.CommitMessage,
+		})
+		if existingPullRequest != nil {
+			plan.AddIntent(IntentUpdateRef, ghasBranch, nil)
+		} else {
+			plan.AddIntent(IntentCreateRef, ghasBranch, nil)
+		}
+	} else {
+		logger.Infof("Creating commit with %d files on top of %s", len(files), parentSHA)
+		commitSHA, err := m.CreateCommit(org, name, parentSHA, m.Config.CommitMessage, files)
 		if err != nil {
-			logger.Errorf("failed to update workflow file, skipping repo: %v", err)
+			logger.Errorf("failed to create commit, skipping repo: %v", err)
 			return
 		}
-		logger.Debugf("Updated workflow file")
+		logger.Debugf("Created commit %s", commitSHA)
+
+		if existingPullRequest != nil {
+			logger.Infof("Updating branch %s", ghasBranch)
+			err = m.UpdateRef(org, name, ghasBranch, commitSHA, false)
+			if err != nil {
+				logger.Errorf("failed to update branch %s, skipping repo: %v", ghasBranch, err)
+				return
+			}
+			tx.RecordRefUpdate(ghasBranch, parentSHA)
+			logger.Debugf("Updated branch %s", ghasBranch)
+		} else {
+			logger.Infof("Creating branch %s", ghasBranch)
+			err = m.CreateRef(org, name, ghasBranch, commitSHA)
+			if err != nil {
+				logger.Errorf("failed to create branch %s, skipping repo: %v", ghasBranch, err)
+				return
+			}
+			tx.RecordRef(ghasBranch)
+			logger.Debugf("Created branch %s", ghasBranch)
+		}
 	}
 
-	logger.Infof("Checking if '.github/emass.json' exists")
-	emassExists, err := m.FileExists(org, name, ".github/emass.json")
-	if err != nil {
-		logger.Errorf("failed to check if emass.json exists, skipping repo: %v", err)
+	if m.Config.PlanMode {
+		plan.Status = PlanStatusConfigure
+		plan.PullRequest = &PlannedPullRequest{
+			Title: PullRequestTitle,
+			Head:  ghasBranch,
+			Base:  defaultBranch,
+			Body:  body,
+		}
+		if existingPullRequest != nil {
+			plan.PullRequest.Number = existingPullRequest.GetNumber()
+			plan.AddIntent(IntentUpdatePullRequest, ghasBranch, nil)
+		} else {
+			plan.AddIntent(IntentCreatePullRequest, ghasBranch, nil)
+		}
+		for _, duplicate := range duplicatePullRequests {
+			plan.AddIntent(IntentClosePullRequest, duplicate.GetHead().GetRef(), map[string]string{
+				"number": strconv.Itoa(duplicate.GetNumber()),
+			})
+		}
+		for _, feature := range securityFeatures {
+			plan.AddIntent(IntentEnableSecurityFeature, feature, nil)
+		}
+		plan.AddIntent(IntentInstallVerifyScansApp, name, nil)
+		branches, err := m.ListEnablementBranches(org, name)
+		if err != nil {
+			logger.Warnf("Failed to retrieve stale branches, skipping cleanup: %v", err)
+		}
+		for _, branch := range branches {
+			if branch != ghasBranch {
+				plan.AddIntent(IntentDeleteRef, branch, nil)
+			}
+		}
+		logger.WithField("event", logging.EventPlanned).Infof("Plan mode enabled, recorded repository configuration")
 		return
 	}
-	if !emassExists {
-		logger.Infof("emass.json does not exist, creating file")
-		err = m.CreateFile(org, name, ghasBranch, ".github/emass.json", "Create emass.json", emassJSON)
+
+	if existingPullRequest != nil {
+		logger.Infof("Refreshing pull request #%d", existingPullRequest.GetNumber())
+		err = m.UpdatePullRequest(org, name, existingPullRequest.GetNumber(), PullRequestTitle, body)
 		if err != nil {
-			logger.Errorf("failed to create emass.json, skipping repo: %v", err)
+			logger.Errorf("failed to refresh pull request, skipping repo: %v", err)
 			return
 		}
-		logger.Debugf("Created emass.json")
+		tx.RecordPullRequestUpdate(existingPullRequest.GetNumber(), existingPullRequest.GetTitle(), existingPullRequest.GetBody())
+		logger.WithFields(log.Fields{
+			"event":                   logging.EventRefreshedPullRequest,
+			logging.DetailPullRequest: existingPullRequest.GetNumber(),
+		}).Infof("Refreshed existing pull request #%d", existingPullRequest.GetNumber())
 	} else {
-		logger.Infof("emass.json exists, retrieving SHA for file")
+		logger.Infof("Creating pull request")
+		number, err := m.CreatePullRequest(org, name, ghasBranch, defaultBranch, PullRequestTitle, body)
+		if err != nil {
+			logger.Errorf("failed to create pull request, skipping repo: %v", err)
+			return
+		}
+		tx.RecordPullRequest(number)
+		logger.Debugf("Created pull request #%d", number)
 	}
 
-	logger.Infof("Generating pull request body with supported languages: [%s]", strings.Join(languages, ", "))
-	body := GeneratePullRequestBody(m.Config.PullRequestBody, org, name, ghasBranch, languages)
-	logger.Debugf("Pull request body: %s", body)
-
-	logger.Infof("Creating pull request")
-	err = m.CreatePullRequest(org, name, ghasBranch, defaultBranch, PullRequestTitle, body)
-	if err != nil {
-		logger.Errorf("failed to create pull request, skipping repo: %v", err)
-		return
+	if len(securityFeatures) > 0 {
+		logger.Infof("Enabling security features required by rollout policy '%s'", policy.Name)
+		err = m.EnableSecurityFeatures(repo, securityFeatures, logger, tx)
+		if err != nil {
+			logger.Errorf("failed to enable security features, skipping repo: %v", err)
+			return
+		}
+		logger.Debugf("Enabled %d security features", len(securityFeatures))
 	}
-	logger.Debugf("Created pull request")
 
 	logger.Infof("Installing Verify Scans app")
 	err = m.InstallVerifyScansApp(repo.GetID())
@@ -224,6 +696,70 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		logger.Errorf("failed to install Verify Scans app, skipping repo: %v", err)
 		return
 	}
-	logger.WithField("event", "installed-verify-scans-application").Infof("Successfully installed 'verify-scans' app on repository")
-	logger.WithField("event", "successfully-configured").Infof("Repository successfully configured")
+	tx.Commit()
+	logger.WithField("event", logging.EventInstalledVerifyScansApplication).Infof("Successfully installed 'verify-scans' app on repository")
+
+	for _, duplicate := range duplicatePullRequests {
+		logger.Infof("Closing duplicate pull request #%d", duplicate.GetNumber())
+		err = m.ClosePullRequest(org, name, duplicate.GetNumber())
+		if err != nil {
+			logger.Warnf("Failed to close duplicate pull request #%d: %v", duplicate.GetNumber(), err)
+			continue
+		}
+		logger.WithFields(log.Fields{
+			"event":                   logging.EventClosedDuplicatePullRequest,
+			logging.DetailPullRequest: duplicate.GetNumber(),
+		}).Infof("Closed duplicate pull request #%d", duplicate.GetNumber())
+	}
+
+	logger.Infof("Retrieving stale '%s' branches", SourceBranchName)
+	branches, err := m.ListEnablementBranches(org, name)
+	if err != nil {
+		logger.Warnf("Failed to retrieve stale branches, skipping cleanup: %v", err)
+	} else {
+		for _, branch := range branches {
+			if branch == ghasBranch {
+				continue
+			}
+			logger.Infof("Deleting stale branch %s", branch)
+			err = m.DeleteRef(org, name, branch)
+			if err != nil {
+				logger.Warnf("Failed to delete stale branch %s: %v", branch, err)
+				continue
+			}
+			logger.WithField("event", logging.EventDeletedStaleBranch).Infof("Deleted stale branch %s", branch)
+		}
+	}
+
+	logger.WithField("event", logging.EventSuccessfullyConfigured).Infof("Repository successfully configured")
+}
+
+func (m *Manager) addPlan(plan *RepositoryPlan) {
+	m.plansMutex.Lock()
+	defer m.plansMutex.Unlock()
+
+	m.Plans = append(m.Plans, plan)
+}
+
+func (m *Manager) VerifyScansInstalled(name string) bool {
+	m.installedMutex.Lock()
+	defer m.installedMutex.Unlock()
+
+	return Contains(m.VerifiedScansAppInstalledRepos, name)
+}
+
+func (m *Manager) SetVerifyScansInstalled(name string, installed bool) {
+	m.installedMutex.Lock()
+	defer m.installedMutex.Unlock()
+
+	var repos []string
+	for _, repo := range m.VerifiedScansAppInstalledRepos {
+		if repo != name {
+			repos = append(repos, repo)
+		}
+	}
+	if installed {
+		repos = append(repos, name)
+	}
+	m.VerifiedScansAppInstalledRepos = repos
 }
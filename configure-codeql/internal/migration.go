@@ -0,0 +1,71 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	DefaultSetupMarker = "<!-- configure-codeql-default-setup -->"
+)
+
+func (m *Manager) MigrateDefaultSetup(repo *github.Repository, pullRequest *github.PullRequest, logger *log.Entry, plan *RepositoryPlan) {
+	org := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+	defaultBranch := repo.GetDefaultBranch()
+
+	logger.Infof("Checking if default code scanning is still enabled")
+	enabled, err := m.DefaultCodeScanningEnabled(org, name)
+	if err != nil {
+		logger.Errorf("failed to check if repository is using default code scanning, skipping migration: %v", err)
+		return
+	}
+	if !enabled {
+		logger.Debugf("Default code scanning is not enabled, no migration required")
+		return
+	}
+
+	logger.Infof("Checking for 'ois-*' analyses on branch %s since pull request #%d was merged", defaultBranch, pullRequest.GetNumber())
+	categories, err := m.ListOISAnalysisCategories(org, name, defaultBranch, pullRequest.GetMergedAt().Time)
+	if err != nil {
+		logger.Errorf("failed to list code scanning analyses, skipping migration: %v", err)
+		return
+	}
+	if len(categories) == 0 {
+		logger.WithField("event", logging.EventDefaultSetupAwaitingAnalysis).Infof("Default code scanning is still enabled, waiting for the first 'ois-*' analysis before disabling it")
+		return
+	}
+	logger.Debugf("Found analyses for categories: [%s]", strings.Join(categories, ", "))
+
+	body := fmt.Sprintf("The reusable CodeQL workflow has uploaded analyses for: %s. GitHub default code scanning setup has been disabled to prevent duplicate analyses.\n\n%s", strings.Join(categories, ", "), DefaultSetupMarker)
+	if m.Config.PlanMode {
+		plan.AddIntent(IntentDisableDefaultSetup, name, map[string]string{
+			"categories": strings.Join(categories, ","),
+		})
+		plan.AddIntent(IntentCreateComment, fmt.Sprintf("#%d", pullRequest.GetNumber()), map[string]string{
+			"body": body,
+		})
+		logger.WithField("event", logging.EventPlanned).Infof("Plan mode enabled, recorded default code scanning migration")
+		return
+	}
+
+	logger.Infof("Disabling default code scanning")
+	err = m.DisableDefaultCodeScanning(org, name)
+	if err != nil {
+		logger.Errorf("failed to disable default code scanning, skipping migration: %v", err)
+		return
+	}
+	logger.WithField("event", logging.EventMigratedDefaultSetup).Infof("Disabled default code scanning after 'ois-*' analyses were uploaded for: [%s]", strings.Join(categories, ", "))
+
+	logger.Infof("Recording migration on pull request #%d", pullRequest.GetNumber())
+	err = m.CreateComment(org, name, pullRequest.GetNumber(), body)
+	if err != nil {
+		logger.Errorf("failed to record migration on pull request: %v", err)
+		return
+	}
+	logger.Debugf("Recorded migration on pull request #%d", pullRequest.GetNumber())
+}
@@ -0,0 +1,97 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+const (
+	PlanStatusConfigure = "configure"
+	PlanStatusError     = "error"
+	PlanStatusFollowUp  = "follow-up"
+	PlanStatusInstall   = "install-verify-scans-app"
+	PlanStatusSkipped   = "skipped"
+
+	IntentClosePullRequest      = "close-pull-request"
+	IntentCreateComment         = "create-comment"
+	IntentCreateCommit          = "create-commit"
+	IntentCreatePullRequest     = "create-pull-request"
+	IntentCreateFile            = "create-file"
+	IntentCreateRef             = "create-ref"
+	IntentDeleteRef             = "delete-ref"
+	IntentDisableDefaultSetup   = "disable-default-setup"
+	IntentEnableSecurityFeature = "enable-security-feature"
+	IntentInstallVerifyScansApp = "install-verify-scans-app"
+	IntentSendEmail             = "send-email"
+	IntentUpdateFile            = "update-file"
+	IntentUpdatePullRequest     = "update-pull-request"
+	IntentUpdateRef             = "update-ref"
+)
+
+type RepositoryPlan struct {
+	Repository    string              `json:"repository"`
+	DefaultBranch string              `json:"default_branch"`
+	Status        string              `json:"status"`
+	SkipReason    string              `json:"skip_reason,omitempty"`
+	Policy        string              `json:"policy,omitempty"`
+	Schedule      string              `json:"schedule,omitempty"`
+	Languages     []string            `json:"languages,omitempty"`
+	Branch        string              `json:"branch,omitempty"`
+	PullRequest   *PlannedPullRequest `json:"pull_request,omitempty"`
+	Intents       []Intent            `json:"intents"`
+}
+
+type PlannedPullRequest struct {
+	Number int    `json:"number,omitempty"`
+	Title  string `json:"title"`
+	Head   string `json:"head"`
+	Base   string `json:"base"`
+	Body   string `json:"body"`
+}
+
+type Intent struct {
+	Action  string            `json:"action"`
+	Target  string            `json:"target"`
+	Details map[string]string `json:"details,omitempty"`
+}
+
+func NewRepositoryPlan(repository, defaultBranch string) *RepositoryPlan {
+	return &RepositoryPlan{
+		Repository:    repository,
+		DefaultBranch: defaultBranch,
+		Status:        PlanStatusError,
+		Intents:       []Intent{},
+	}
+}
+
+func (p *RepositoryPlan) Skip(reason string) {
+	p.Status = PlanStatusSkipped
+	p.SkipReason = reason
+}
+
+func (p *RepositoryPlan) AddIntent(action, target string, details map[string]string) {
+	p.Intents = append(p.Intents, Intent{
+		Action:  action,
+		Target:  target,
+		Details: details,
+	})
+}
+
+func WritePlans(path string, plans []*RepositoryPlan) error {
+	if plans == nil {
+		plans = []*RepositoryPlan{}
+	}
+
+	planBytes, err := json.MarshalIndent(plans, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal plans: %w", err)
+	}
+
+	err = os.WriteFile(path, planBytes, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write plans: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,395 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
+	"github.com/google/go-github/v52/github"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultPolicyName = "default"
+	DefaultRunner     = "ubuntu-latest"
+	DefaultRunnerKey  = "default"
+	DefaultMatrixPath = "."
+)
+
+var DefaultLanguageRunners = map[string]RunnerLabels{
+	"swift": {"macos-latest"},
+}
+
+var weekdays = []string{"sunday", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday"}
+
+type RolloutPolicy struct {
+	Defaults        PolicySettings    `yaml:"defaults"`
+	Policies        []PolicySelection `yaml:"policies"`
+	RepositoryTypes RepositoryTypes   `yaml:"repository_types"`
+}
+
+type PolicySelection struct {
+	Name           string      `yaml:"name"`
+	Match          PolicyMatch `yaml:"match"`
+	PolicySettings `yaml:",inline"`
+}
+
+type PolicyMatch struct {
+	Names            []string            `yaml:"names"`
+	Topics           []string            `yaml:"topics"`
+	Visibility       []string            `yaml:"visibility"`
+	CustomProperties map[string][]string `yaml:"custom_properties"`
+	Languages        []string            `yaml:"languages"`
+}
+
+type PolicySettings struct {
+	Exclude         *bool                   `yaml:"exclude"`
+	Runners         map[string]RunnerLabels `yaml:"runners"`
+	ScheduleWindows []ScheduleWindow        `yaml:"schedule_windows"`
+	Branches        []string                `yaml:"branches"`
+	UploadDB        *bool                   `yaml:"upload_db"`
+	Config          string                  `yaml:"config"`
+	Matrix          []MatrixInclude         `yaml:"matrix"`
+	Security        map[string]bool         `yaml:"security"`
+}
+
+type ScheduleWindow struct {
+	Days      []string `yaml:"days"`
+	StartHour int      `yaml:"start_hour"`
+	EndHour   int      `yaml:"end_hour"`
+}
+
+type RunnerLabels []string
+
+type RepositoryAttributes struct {
+	Name             string
+	Topics           []string
+	Visibility       string
+	CustomProperties map[string][]string
+	Languages        []string
+	DotNetFramework  bool
+}
+
+type RepositoryPolicy struct {
+	Name            string
+	Exclude         bool
+	Runners         map[string]RunnerLabels
+	ScheduleWindows []ScheduleWindow
+	Branches        []string
+	UploadDB        bool
+	Config          string
+	Matrix          []MatrixInclude
+	Security        map[string]bool
+}
+
+func ParseRolloutPolicy(content string) (*RolloutPolicy, error) {
+	policy := &RolloutPolicy{}
+	err := yaml.Unmarshal([]byte(content), policy)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal rollout policy: %w", err)
+	}
+
+	err = policy.Defaults.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid defaults: %w", err)
+	}
+	err = policy.RepositoryTypes.validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid repository types: %w", err)
+	}
+	for i, selection := range policy.Policies {
+		if selection.Name == "" {
+			return nil, fmt.Errorf("policy %d is missing a name", i)
+		}
+		err = selection.Match.validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid match for policy %s: %w", selection.Name, err)
+		}
+		err = selection.PolicySettings.validate()
+		if err != nil {
+			return nil, fmt.Errorf("invalid settings for policy %s: %w", selection.Name, err)
+		}
+	}
+
+	return policy, nil
+}
+
+func (p *RolloutPolicy) UsesCustomProperties() bool {
+	for _, selection := range p.Policies {
+		if len(selection.Match.CustomProperties) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *RolloutPolicy) Resolve(attributes RepositoryAttributes) *RepositoryPolicy {
+	resolved := &RepositoryPolicy{
+		Name: DefaultPolicyName,
+		Runners: map[string]RunnerLabels{
+			DefaultRunnerKey: {DefaultRunner},
+		},
+		Security: map[string]bool{},
+	}
+	for language, labels := range DefaultLanguageRunners {
+		resolved.Runners[language] = labels
+	}
+	if attributes.DotNetFramework {
+		resolved.Runners["csharp"] = RunnerLabels{DotNetFrameworkRunner}
+	}
+	resolved.apply(p.Defaults)
+
+	for _, selection := range p.Policies {
+		if selection.Match.Matches(attributes) {
+			resolved.Name = selection.Name
+			resolved.apply(selection.PolicySettings)
+			break
+		}
+	}
+
+	return resolved
+}
+
+func (m PolicyMatch) Matches(attributes RepositoryAttributes) bool {
+	if len(m.Names) > 0 {
+		matched := false
+		for _, pattern := range m.Names {
+			if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(attributes.Name)); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(m.Topics) > 0 && !intersects(m.Topics, attributes.Topics) {
+		return false
+	}
+	if len(m.Visibility) > 0 && !intersects(m.Visibility, []string{attributes.Visibility}) {
+		return false
+	}
+	for property, values := range m.CustomProperties {
+		if !intersects(values, attributes.CustomProperties[property]) {
+			return false
+		}
+	}
+	if len(m.Languages) > 0 && !intersects(m.Languages, attributes.Languages) {
+		return false
+	}
+
+	return true
+}
+
+func (m PolicyMatch) validate() error {
+	for _, pattern := range m.Names {
+		_, err := path.Match(pattern, "")
+		if err != nil {
+			return fmt.Errorf("invalid name pattern %s: %w", pattern, err)
+		}
+	}
+	for _, visibility := range m.Visibility {
+		switch strings.ToLower(visibility) {
+		case "public", "private", "internal":
+		default:
+			return fmt.Errorf("invalid visibility %s", visibility)
+		}
+	}
+	for _, language := range m.Languages {
+		if !utils.IsSupportedCodeQLLanguage(strings.ToLower(language)) {
+			return fmt.Errorf("unsupported language %s", language)
+		}
+	}
+
+	return nil
+}
+
+func (s PolicySettings) validate() error {
+	for language, labels := range s.Runners {
+		if language != DefaultRunnerKey && !utils.IsSupportedCodeQLLanguage(language) {
+			return fmt.Errorf("runners configured for unsupported language %s", language)
+		}
+		if len(labels) == 0 {
+			return fmt.Errorf("no runner labels configured for %s", language)
+		}
+	}
+	for _, entry := range s.Matrix {
+		if !utils.IsSupportedCodeQLLanguage(entry.Language) {
+			return fmt.Errorf("matrix entry configured for unsupported language %s", entry.Language)
+		}
+	}
+	for feature := range s.Security {
+		if _, ok := SecurityFeatureNames[feature]; !ok {
+			return fmt.Errorf("unsupported security feature %s", feature)
+		}
+	}
+	for _, window := range s.ScheduleWindows {
+		if window.StartHour < 0 || window.EndHour > 24 || window.StartHour >= window.EndHour {
+			return fmt.Errorf("invalid schedule window %d-%d, hours must satisfy 0 <= start_hour < end_hour <= 24", window.StartHour, window.EndHour)
+		}
+		for _, day := range window.Days {
+			if ParseWeekday(day) < 0 {
+				return fmt.Errorf("invalid schedule window day %s", day)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (p *RepositoryPolicy) apply(settings PolicySettings) {
+	if settings.Exclude != nil {
+		p.Exclude = *settings.Exclude
+	}
+	for language, labels := range settings.Runners {
+		p.Runners[language] = labels
+	}
+	if len(settings.ScheduleWindows) > 0 {
+		p.ScheduleWindows = settings.ScheduleWindows
+	}
+	if len(settings.Branches) > 0 {
+		p.Branches = settings.Branches
+	}
+	if settings.UploadDB != nil {
+		p.UploadDB = *settings.UploadDB
+	}
+	if settings.Config != "" {
+		p.Config = settings.Config
+	}
+	if len(settings.Matrix) > 0 {
+		p.Matrix = settings.Matrix
+	}
+	for feature, enabled := range settings.Security {
+		p.Security[feature] = enabled
+	}
+}
+
+func (p *RepositoryPolicy) MatrixEntries(languages []string) []MatrixInclude {
+	var entries []MatrixInclude
+	for _, language := range languages {
+		overridden := false
+		for _, entry := range p.Matrix {
+			if entry.Language != language {
+				continue
+			}
+			overridden = true
+			if len(entry.RunsOn) == 0 {
+				entry.RunsOn = p.Runner(language)
+			}
+			if entry.Path == "" {
+				entry.Path = DefaultMatrixPath
+			}
+			entries = append(entries, entry)
+		}
+		if overridden {
+			continue
+		}
+
+		entries = append(entries, MatrixInclude{
+			Language: language,
+			RunsOn:   p.Runner(language),
+			Path:     DefaultMatrixPath,
+		})
+	}
+
+	return entries
+}
+
+func (p *RepositoryPolicy) Runner(language string) RunnerLabels {
+	if labels, ok := p.Runners[language]; ok {
+		return labels
+	}
+
+	return p.Runners[DefaultRunnerKey]
+}
+
+func (w ScheduleWindow) Weekdays() []int {
+	if len(w.Days) == 0 {
+		return []int{0, 1, 2, 3, 4, 5, 6}
+	}
+
+	var days []int
+	for _, day := range w.Days {
+		days = append(days, ParseWeekday(day))
+	}
+
+	return days
+}
+
+func ParseWeekday(day string) int {
+	day = strings.ToLower(strings.TrimSpace(day))
+	for i, weekday := range weekdays {
+		if day == weekday || day == weekday[:3] || day == fmt.Sprint(i) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func (r *RunnerLabels) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*r = RunnerLabels{value.Value}
+		return nil
+	}
+
+	var labels []string
+	err := value.Decode(&labels)
+	if err != nil {
+		return err
+	}
+	*r = labels
+
+	return nil
+}
+
+func (r RunnerLabels) MarshalYAML() (interface{}, error) {
+	if len(r) == 1 {
+		return r[0], nil
+	}
+
+	return []string(r), nil
+}
+
+func (r RunnerLabels) String() string {
+	return strings.Join(r, ",")
+}
+
+func (m *Manager) ResolvePolicy(repo *github.Repository, languages []string) (*RepositoryPolicy, error) {
+	attributes := RepositoryAttributes{
+		Name:       repo.GetName(),
+		Topics:     repo.Topics,
+		Visibility: repo.GetVisibility(),
+		Languages:  languages,
+	}
+	if m.Policy.UsesCustomProperties() {
+		properties, err := utils.ListCustomPropertyValues(m.Context, m.AdminGitHubClient, repo.GetOwner().GetLogin(), repo.GetName())
+		if err != nil {
+			return nil, err
+		}
+		attributes.CustomProperties = properties
+	}
+	if Contains(languages, "csharp") {
+		dotNetFramework, err := m.UsesDotNetFramework(repo.GetOwner().GetLogin(), repo.GetName(), repo.GetDefaultBranch())
+		if err != nil {
+			return nil, err
+		}
+		attributes.DotNetFramework = dotNetFramework
+	}
+
+	return m.Policy.Resolve(attributes), nil
+}
+
+func intersects(expected, actual []string) bool {
+	for _, e := range expected {
+		for _, a := range actual {
+			if strings.EqualFold(e, a) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,327 @@
+package internal
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testRolloutPolicy = `
+defaults:
+  runners:
+    default: ubuntu-22.04
+  branches: [develop]
+  upload_db: false
+policies:
+  - name: excluded
+    match:
+      names: [sandbox-*]
+    exclude: true
+  - name: mobile
+    match:
+      topics: [Mobile]
+      visibility: [private, internal]
+    runners:
+      swift: [self-hosted, macos]
+    upload_db: true
+  - name: regulated
+    match:
+      custom_properties:
+        data-classification: [phi, pii]
+    config: .github/codeql/regulated.yml
+  - name: go
+    match:
+      languages: [go]
+    branches: [main, release]
+  - name: mobile-catch-all
+    match:
+      topics: [mobile]
+    upload_db: false
+`
+
+func TestRolloutPolicyResolve(t *testing.T) {
+	policy, err := ParseRolloutPolicy(testRolloutPolicy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		attributes RepositoryAttributes
+		policy     string
+		exclude    bool
+		uploadDB   bool
+		branches   []string
+		config     string
+		swift      RunnerLabels
+	}{
+		{
+			name:       "defaults when nothing matches",
+			attributes: RepositoryAttributes{Name: "api", Visibility: "public"},
+			policy:     DefaultPolicyName,
+			branches:   []string{"develop"},
+			swift:      RunnerLabels{"macos-latest"},
+		},
+		{
+			name:       "name pattern is case insensitive",
+			attributes: RepositoryAttributes{Name: "Sandbox-Test"},
+			policy:     "excluded",
+			exclude:    true,
+			branches:   []string{"develop"},
+			swift:      RunnerLabels{"macos-latest"},
+		},
+		{
+			name:       "first matching policy wins",
+			attributes: RepositoryAttributes{Name: "ios-app", Topics: []string{"mobile"}, Visibility: "private"},
+			policy:     "mobile",
+			uploadDB:   true,
+			branches:   []string{"develop"},
+			swift:      RunnerLabels{"self-hosted", "macos"},
+		},
+		{
+			name:       "all match criteria must match",
+			attributes: RepositoryAttributes{Name: "ios-app", Topics: []string{"mobile"}, Visibility: "public"},
+			policy:     "mobile-catch-all",
+			branches:   []string{"develop"},
+			swift:      RunnerLabels{"macos-latest"},
+		},
+		{
+			name:       "custom property matches any value",
+			attributes: RepositoryAttributes{Name: "claims", CustomProperties: map[string][]string{"data-classification": {"pii"}}},
+			policy:     "regulated",
+			branches:   []string{"develop"},
+			config:     ".github/codeql/regulated.yml",
+			swift:      RunnerLabels{"macos-latest"},
+		},
+		{
+			name:       "custom property with other value",
+			attributes: RepositoryAttributes{Name: "claims", CustomProperties: map[string][]string{"data-classification": {"public"}}},
+			policy:     DefaultPolicyName,
+			branches:   []string{"develop"},
+			swift:      RunnerLabels{"macos-latest"},
+		},
+		{
+			name:       "language match overrides default branches",
+			attributes: RepositoryAttributes{Name: "cli", Languages: []string{"python", "go"}},
+			policy:     "go",
+			branches:   []string{"main", "release"},
+			swift:      RunnerLabels{"macos-latest"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolved := policy.Resolve(test.attributes)
+			if resolved.Name != test.policy {
+				t.Fatalf("got policy %s, want %s", resolved.Name, test.policy)
+			}
+			if resolved.Exclude != test.exclude {
+				t.Errorf("got exclude %t, want %t", resolved.Exclude, test.exclude)
+			}
+			if resolved.UploadDB != test.uploadDB {
+				t.Errorf("got upload_db %t, want %t", resolved.UploadDB, test.uploadDB)
+			}
+			if !reflect.DeepEqual(resolved.Branches, test.branches) {
+				t.Errorf("got branches %v, want %v", resolved.Branches, test.branches)
+			}
+			if resolved.Config != test.config {
+				t.Errorf("got config %s, want %s", resolved.Config, test.config)
+			}
+			if !reflect.DeepEqual(resolved.Runner("swift"), test.swift) {
+				t.Errorf("got swift runner %v, want %v", resolved.Runner("swift"), test.swift)
+			}
+			if !reflect.DeepEqual(resolved.Runner("go"), RunnerLabels{"ubuntu-22.04"}) {
+				t.Errorf("got default runner %v, want the runner from defaults", resolved.Runner("go"))
+			}
+		})
+	}
+}
+
+func TestEmptyRolloutPolicyResolve(t *testing.T) {
+	resolved := (&RolloutPolicy{}).Resolve(RepositoryAttributes{Name: "api"})
+	if resolved.Name != DefaultPolicyName {
+		t.Errorf("got policy %s, want %s", resolved.Name, DefaultPolicyName)
+	}
+	if !reflect.DeepEqual(resolved.Runner("go"), RunnerLabels{DefaultRunner}) {
+		t.Errorf("got runner %v, want %s", resolved.Runner("go"), DefaultRunner)
+	}
+	if !reflect.DeepEqual(resolved.Runner("swift"), DefaultLanguageRunners["swift"]) {
+		t.Errorf("got swift runner %v, want %v", resolved.Runner("swift"), DefaultLanguageRunners["swift"])
+	}
+}
+
+func TestRolloutPolicyResolveDotNetFramework(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     *RolloutPolicy
+		attributes RepositoryAttributes
+		runner     RunnerLabels
+	}{
+		{
+			name:   "modern dotnet uses the default runner",
+			policy: &RolloutPolicy{},
+			runner: RunnerLabels{DefaultRunner},
+		},
+		{
+			name:       "dotnet framework uses windows",
+			policy:     &RolloutPolicy{},
+			attributes: RepositoryAttributes{DotNetFramework: true},
+			runner:     RunnerLabels{DotNetFrameworkRunner},
+		},
+		{
+			name:       "policy runner overrides the dotnet framework default",
+			policy:     &RolloutPolicy{Defaults: PolicySettings{Runners: map[string]RunnerLabels{"csharp": {"self-hosted", "windows"}}}},
+			attributes: RepositoryAttributes{DotNetFramework: true},
+			runner:     RunnerLabels{"self-hosted", "windows"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			runner := test.policy.Resolve(test.attributes).Runner("csharp")
+			if !reflect.DeepEqual(runner, test.runner) {
+				t.Errorf("got runner %v, want %v", runner, test.runner)
+			}
+		})
+	}
+}
+
+func TestParseRolloutPolicyValidation(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name:    "missing policy name",
+			content: "policies:\n  - match:\n      names: [api]\n",
+			err:     "missing a name",
+		},
+		{
+			name:    "invalid name pattern",
+			content: "policies:\n  - name: bad\n    match:\n      names: ['[']\n",
+			err:     "invalid name pattern",
+		},
+		{
+			name:    "invalid visibility",
+			content: "policies:\n  - name: bad\n    match:\n      visibility: [secret]\n",
+			err:     "invalid visibility",
+		},
+		{
+			name:    "unsupported match language",
+			content: "policies:\n  - name: bad\n    match:\n      languages: [cobol]\n",
+			err:     "unsupported language",
+		},
+		{
+			name:    "unsupported runner language",
+			content: "defaults:\n  runners:\n    cobol: ubuntu-latest\n",
+			err:     "unsupported language cobol",
+		},
+		{
+			name:    "empty runner labels",
+			content: "defaults:\n  runners:\n    go: []\n",
+			err:     "no runner labels",
+		},
+		{
+			name:    "unsupported matrix language",
+			content: "defaults:\n  matrix:\n    - language: cobol\n",
+			err:     "matrix entry configured for unsupported language",
+		},
+		{
+			name:    "unsupported security feature",
+			content: "defaults:\n  security:\n    code_review: true\n",
+			err:     "unsupported security feature",
+		},
+		{
+			name:    "window ends before it starts",
+			content: "defaults:\n  schedule_windows:\n    - start_hour: 6\n      end_hour: 2\n",
+			err:     "invalid schedule window",
+		},
+		{
+			name:    "window past midnight",
+			content: "defaults:\n  schedule_windows:\n    - start_hour: 20\n      end_hour: 25\n",
+			err:     "invalid schedule window",
+		},
+		{
+			name:    "invalid window day",
+			content: "defaults:\n  schedule_windows:\n    - days: [someday]\n      start_hour: 0\n      end_hour: 6\n",
+			err:     "invalid schedule window day",
+		},
+		{
+			name:    "invalid repository type action",
+			content: "repository_types:\n  forks: ignore\n",
+			err:     "forks must be one of",
+		},
+		{
+			name:    "full day window",
+			content: "defaults:\n  schedule_windows:\n    - days: [sat, sunday, 1]\n      start_hour: 0\n      end_hour: 24\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseRolloutPolicy(test.content)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("got error %v, want error containing %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestRepositoryPolicyMatrixEntries(t *testing.T) {
+	policy, err := ParseRolloutPolicy(`
+defaults:
+  matrix:
+    - language: csharp
+      runs-on: windows-latest
+      build_step_name: msbuild
+    - language: java
+      path: services/api
+    - language: java
+      path: services/worker
+`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	entries := policy.Resolve(RepositoryAttributes{}).MatrixEntries([]string{"csharp", "java", "swift", "go"})
+	want := []MatrixInclude{
+		{Language: "csharp", RunsOn: RunnerLabels{"windows-latest"}, BuildStepName: "msbuild", Path: DefaultMatrixPath},
+		{Language: "java", RunsOn: RunnerLabels{DefaultRunner}, Path: "services/api"},
+		{Language: "java", RunsOn: RunnerLabels{DefaultRunner}, Path: "services/worker"},
+		{Language: "swift", RunsOn: RunnerLabels{"macos-latest"}, Path: DefaultMatrixPath},
+		{Language: "go", RunsOn: RunnerLabels{DefaultRunner}, Path: DefaultMatrixPath},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("got entries %+v, want %+v", entries, want)
+	}
+}
+
+func TestRepositoryTypesActions(t *testing.T) {
+	tests := []struct {
+		name     string
+		types    RepositoryTypes
+		fork     string
+		template string
+	}{
+		{name: "defaults", fork: RepositoryTypeConfigure, template: RepositoryTypeConfigure},
+		{name: "skip", types: RepositoryTypes{Forks: "Skip", Templates: "skip"}, fork: RepositoryTypeSkip, template: RepositoryTypeSkip},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if action := test.types.ForkAction(); action != test.fork {
+				t.Errorf("got fork action %s, want %s", action, test.fork)
+			}
+			if action := test.types.TemplateAction(); action != test.template {
+				t.Errorf("got template action %s, want %s", action, test.template)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+type PullRequestData struct {
+	Org              string
+	Repo             string
+	DefaultBranch    string
+	Branch           string
+	Languages        []string
+	Files            []string
+	WorkflowReplaced bool
+	WorkflowChanges  map[string][]string
+	EMASS            EMASS
+	EMASSMapped      bool
+	Checklist        []string
+	BuildSteps       []BuildStepProposal
+	SecurityFeatures []string
+	Links            PullRequestLinks
+}
+
+type PullRequestLinks struct {
+	EMASSDocumentation string
+}
+
+func ParsePullRequestTemplate(text, emassDocumentationURL string) (*template.Template, error) {
+	tmpl, err := template.New("pull_request_body").Option("missingkey=error").Funcs(template.FuncMap{
+		"join": strings.Join,
+	}).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse pull request body template: %w", err)
+	}
+
+	sample := PullRequestData{
+		Org:              "org",
+		Repo:             "repo",
+		DefaultBranch:    "main",
+		Branch:           SourceBranchName,
+		Languages:        []string{"javascript"},
+		Files:            []string{DefaultWorkflowPath, ".github/emass.json"},
+		WorkflowReplaced: true,
+		WorkflowChanges: map[string][]string{
+			DefaultWorkflowPath: {"change"},
+		},
+		EMASS: EMASS{
+			SystemID:         1,
+			SystemName:       "system",
+			SystemOwnerName:  "owner",
+			SystemOwnerEmail: "owner@example.com",
+		},
+		EMASSMapped: true,
+		Checklist:   []string{"item"},
+		BuildSteps: []BuildStepProposal{
+			{
+				Language:   "java",
+				Marker:     "pom.xml",
+				Steps:      "mvn -B -DskipTests package",
+				Confidence: ConfidenceHigh,
+				Reason:     "Found `pom.xml`",
+			},
+		},
+		SecurityFeatures: []string{SecurityFeatureSecretScanning},
+		Links: PullRequestLinks{
+			EMASSDocumentation: emassDocumentationURL,
+		},
+	}
+	err = tmpl.Execute(io.Discard, sample)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate pull request body template: %w", err)
+	}
+
+	return tmpl, nil
+}
+
+func RenderPullRequestBody(tmpl *template.Template, data PullRequestData) (string, error) {
+	var buffer bytes.Buffer
+	err := tmpl.Execute(&buffer, data)
+	if err != nil {
+		return "", fmt.Errorf("failed to render pull request body: %w", err)
+	}
+	body := strings.TrimRight(buffer.String(), "\n")
+
+	var paths []string
+	for path := range data.WorkflowChanges {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		body = strings.TrimRight(body, "\n") + fmt.Sprintf("\n\n### Changes to `%s`\n\n", path)
+		for _, change := range data.WorkflowChanges[path] {
+			body += fmt.Sprintf("- %s\n", change)
+		}
+	}
+
+	if len(data.BuildSteps) > 0 {
+		body = strings.TrimRight(body, "\n") + fmt.Sprintf("\n\n### Proposed build steps\n\nThe following build steps were added to `%s` based on the build files found in the repository. Please verify they build the code you want analyzed.\n\n", CodeQLConfigPath)
+		body += "| Language | Build steps | Confidence | Reason |\n|---|---|---|---|\n"
+		for _, proposal := range data.BuildSteps {
+			body += fmt.Sprintf("| %s | `%s` | %s | %s |\n", proposal.Language, proposal.Steps, proposal.Confidence, proposal.Reason)
+		}
+	}
+
+	if len(data.SecurityFeatures) > 0 {
+		body = strings.TrimRight(body, "\n") + "\n\n### Security features enabled\n\nThe following security features were enabled on this repository:\n\n"
+		for _, feature := range data.SecurityFeatures {
+			body += fmt.Sprintf("- %s\n", SecurityFeatureNames[feature])
+		}
+	}
+
+	if len(data.Checklist) > 0 {
+		body = strings.TrimRight(body, "\n") + "\n\n### Checklist\n\n"
+		for _, item := range data.Checklist {
+			body += fmt.Sprintf("- [ ] %s\n", item)
+		}
+	}
+
+	return fmt.Sprintf("%s\n\n%s", strings.TrimRight(body, "\n"), PullRequestMarker), nil
+}
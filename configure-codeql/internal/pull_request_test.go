@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParsePullRequestTemplate(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		err  string
+	}{
+		{
+			name: "valid template",
+			text: "Enables CodeQL for {{ .Org }}/{{ .Repo }} on `{{ .DefaultBranch }}` for {{ join .Languages \", \" }}.\n{{ if .EMASSMapped }}System {{ .EMASS.SystemName }}{{ end }}\n[eMASS]({{ .Links.EMASSDocumentation }})",
+		},
+		{
+			name: "unknown field",
+			text: "Enables CodeQL for {{ .Organization }}",
+			err:  "failed to validate pull request body template",
+		},
+		{
+			name: "unknown function",
+			text: "{{ upper .Org }}",
+			err:  "failed to parse pull request body template",
+		},
+		{
+			name: "parse error",
+			text: "Enables CodeQL for {{ .Org",
+			err:  "failed to parse pull request body template",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tmpl, err := ParsePullRequestTemplate(test.text, "https://example.com/emass")
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if tmpl == nil {
+					t.Fatal("got nil template")
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("got no error, want %q", test.err)
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("got error %q, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestRenderPullRequestBody(t *testing.T) {
+	tmpl, err := ParsePullRequestTemplate("Enables CodeQL for {{ .Org }}/{{ .Repo }}: {{ join .Languages \", \" }}\n", "https://example.com/emass")
+	if err != nil {
+		t.Fatalf("failed to parse template: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		data     PullRequestData
+		contains []string
+		excludes []string
+	}{
+		{
+			name: "template only",
+			data: PullRequestData{
+				Org:       "org",
+				Repo:      "repo",
+				Languages: []string{"go", "python"},
+			},
+			contains: []string{"Enables CodeQL for org/repo: go, python\n\n" + PullRequestMarker},
+			excludes: []string{"### Changes to", "### Proposed build steps", "### Security features enabled", "### Checklist"},
+		},
+		{
+			name: "generated sections",
+			data: PullRequestData{
+				Org:       "org",
+				Repo:      "repo",
+				Languages: []string{"java"},
+				WorkflowChanges: map[string][]string{
+					".github/workflows/z.yml": {"Replaced job `analyze`"},
+					".github/workflows/a.yml": {"Moved `debug`"},
+				},
+				BuildSteps: []BuildStepProposal{
+					{
+						Language:   "java",
+						Steps:      "mvn -B -DskipTests package",
+						Confidence: ConfidenceHigh,
+						Reason:     "Found `pom.xml`",
+					},
+				},
+				SecurityFeatures: []string{SecurityFeatureSecretScanning},
+				Checklist:        []string{"Verify the build"},
+			},
+			contains: []string{
+				"### Changes to `.github/workflows/a.yml`\n\n- Moved `debug`\n\n### Changes to `.github/workflows/z.yml`\n\n- Replaced job `analyze`",
+				"| java | `mvn -B -DskipTests package` | high | Found `pom.xml` |",
+				"- " + SecurityFeatureNames[SecurityFeatureSecretScanning],
+				"### Checklist\n\n- [ ] Verify the build\n\n" + PullRequestMarker,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body, err := RenderPullRequestBody(tmpl, test.data)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !strings.HasSuffix(body, PullRequestMarker) {
+				t.Errorf("body does not end with the pull request marker:\n%s", body)
+			}
+			for _, s := range test.contains {
+				if !strings.Contains(body, s) {
+					t.Errorf("body does not contain %q:\n%s", s, body)
+				}
+			}
+			for _, s := range test.excludes {
+				if strings.Contains(body, s) {
+					t.Errorf("body contains %q:\n%s", s, body)
+				}
+			}
+		})
+	}
+}
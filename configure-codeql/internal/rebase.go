@@ -0,0 +1,162 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	MergeableStateDirty = "dirty"
+)
+
+func (m *Manager) PullRequestConflicting(org, repo string, pullRequest *github.PullRequest, logger *log.Entry) (bool, error) {
+	logger.Infof("Checking if pull request #%d has conflicts", pullRequest.GetNumber())
+	detailed, err := m.GetPullRequest(org, repo, pullRequest.GetNumber())
+	if err != nil {
+		return false, err
+	}
+	if detailed.Mergeable == nil {
+		logger.Debugf("Mergeability of pull request #%d has not been computed yet, it will be checked on the next run", pullRequest.GetNumber())
+		return false, nil
+	}
+	logger.Debugf("Pull request #%d is in mergeable state '%s'", pullRequest.GetNumber(), detailed.GetMergeableState())
+
+	return !detailed.GetMergeable() && detailed.GetMergeableState() == MergeableStateDirty, nil
+}
+
+func (m *Manager) RebaseConflictingPullRequest(repo *github.Repository, pullRequest *github.PullRequest, logger *log.Entry, plan *RepositoryPlan) (string, error) {
+	conflicting, err := m.PullRequestConflicting(repo.GetOwner().GetLogin(), repo.GetName(), pullRequest, logger)
+	if err != nil {
+		return "", fmt.Errorf("failed to check pull request for conflicts: %v", err)
+	}
+	if !conflicting {
+		return "", nil
+	}
+
+	logger.Infof("Pull request #%d has conflicts, regenerating branch %s from %s", pullRequest.GetNumber(), pullRequest.GetHead().GetRef(), repo.GetDefaultBranch())
+	rebasedSHA, err := m.RebasePullRequest(repo, pullRequest, logger, plan)
+	if err != nil {
+		return "", fmt.Errorf("failed to regenerate pull request branch: %v", err)
+	}
+	logger.WithFields(log.Fields{
+		"event":                   logging.EventRebasedPullRequest,
+		logging.DetailPullRequest: pullRequest.GetNumber(),
+	}).Infof("Regenerated branch of pull request #%d from %s", pullRequest.GetNumber(), repo.GetDefaultBranch())
+
+	return rebasedSHA, nil
+}
+
+func (m *Manager) RebasePullRequest(repo *github.Repository, pullRequest *github.PullRequest, logger *log.Entry, plan *RepositoryPlan) (string, error) {
+	org := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+	defaultBranch := repo.GetDefaultBranch()
+	branch := pullRequest.GetHead().GetRef()
+	headSHA := pullRequest.GetHead().GetSHA()
+
+	logger.Infof("Retrieving SHA for branch %s", defaultBranch)
+	sha, err := m.GetDefaultRefSHA(org, name, defaultBranch)
+	if err != nil {
+		return "", err
+	}
+	logger.Debugf("Retrieved SHA %s for branch %s", sha, defaultBranch)
+
+	logger.Infof("Retrieving files changed by pull request #%d", pullRequest.GetNumber())
+	changedFiles, err := m.ListPullRequestFiles(org, name, pullRequest.GetNumber())
+	if err != nil {
+		return "", err
+	}
+	logger.Debugf("Retrieved %d changed files", len(changedFiles))
+
+	var languages []string
+	var policy *RepositoryPolicy
+	var files []GeneratedFile
+	for _, changedFile := range changedFiles {
+		path := changedFile.GetFilename()
+		if changedFile.GetStatus() == "removed" {
+			return "", fmt.Errorf("pull request removes '%s', the branch cannot be regenerated", path)
+		}
+
+		content, found, err := m.GetFileContents(org, name, path, headSHA)
+		if err != nil {
+			return "", err
+		}
+		if !found {
+			return "", fmt.Errorf("'%s' not found on branch %s", path, branch)
+		}
+
+		if strings.HasPrefix(path, WorkflowsDirectory+"/") {
+			existing, found, err := m.GetFileContents(org, name, path, sha)
+			if err != nil {
+				return "", err
+			}
+			if found {
+				if policy == nil {
+					languages, err = m.ListSupportedLanguages(org, name)
+					if err != nil {
+						return "", err
+					}
+					policy, err = m.ResolvePolicy(repo, languages)
+					if err != nil {
+						return "", err
+					}
+				}
+
+				logger.Infof("'%s' changed on branch %s, merging CodeQL workflow into the current file", path, defaultBranch)
+				merged, _, converted, err := MergeCodeQLWorkflow(existing, languages, defaultBranch, policy)
+				if err != nil {
+					return "", err
+				}
+				if converted {
+					content = merged
+				} else {
+					logger.Infof("'%s' on branch %s has no CodeQL steps to convert, moving the CodeQL workflow to '%s'", path, defaultBranch, FallbackWorkflowPath)
+					path = FallbackWorkflowPath
+				}
+			}
+		} else if path == ".github/emass.json" {
+			logger.Debugf("Preserving emass.json from branch %s", branch)
+		}
+
+		files = append(files, GeneratedFile{
+			Path:    path,
+			Content: content,
+		})
+	}
+
+	if m.Config.PlanMode {
+		for _, file := range files {
+			plan.AddIntent(IntentUpdateFile, file.Path, map[string]string{
+				"branch":  branch,
+				"content": file.Content,
+			})
+		}
+		plan.AddIntent(IntentCreateCommit, branch, map[string]string{
+			"parent":  sha,
+			"message": m.Config.CommitMessage,
+		})
+		plan.AddIntent(IntentUpdateRef, branch, map[string]string{
+			"force": "true",
+		})
+		return "", nil
+	}
+
+	logger.Infof("Creating commit with %d files on top of %s", len(files), sha)
+	commitSHA, err := m.CreateCommit(org, name, sha, m.Config.CommitMessage, files)
+	if err != nil {
+		return "", err
+	}
+	logger.Debugf("Created commit %s", commitSHA)
+
+	logger.Infof("Force updating branch %s", branch)
+	err = m.UpdateRef(org, name, branch, commitSHA, true)
+	if err != nil {
+		return "", err
+	}
+	logger.Debugf("Updated branch %s", branch)
+
+	return commitSHA, nil
+}
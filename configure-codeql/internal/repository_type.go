@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/google/go-github/v52/github"
+)
+
+const (
+	RepositoryTypeDisabled = "disabled"
+	RepositoryTypeEmpty    = "empty"
+	RepositoryTypeFork     = "fork"
+	RepositoryTypeTemplate = "template"
+
+	RepositoryTypeConfigure = "configure"
+	RepositoryTypeSkip      = "skip"
+)
+
+var RepositoryTypeEvents = map[string]logging.EventType{
+	RepositoryTypeDisabled: logging.EventSkippedDisabled,
+	RepositoryTypeEmpty:    logging.EventSkippedEmpty,
+	RepositoryTypeFork:     logging.EventSkippedFork,
+	RepositoryTypeTemplate: logging.EventSkippedTemplate,
+}
+
+type RepositoryTypes struct {
+	Forks     string `yaml:"forks"`
+	Templates string `yaml:"templates"`
+}
+
+func (t RepositoryTypes) ForkAction() string {
+	return defaultRepositoryTypeAction(t.Forks, RepositoryTypeConfigure)
+}
+
+func (t RepositoryTypes) TemplateAction() string {
+	return defaultRepositoryTypeAction(t.Templates, RepositoryTypeConfigure)
+}
+
+func (t RepositoryTypes) validate() error {
+	for name, action := range map[string]string{"forks": t.Forks, "templates": t.Templates} {
+		switch strings.ToLower(action) {
+		case "", RepositoryTypeConfigure, RepositoryTypeSkip:
+		default:
+			return fmt.Errorf("%s must be one of: %s, %s", name, RepositoryTypeConfigure, RepositoryTypeSkip)
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) SkippedRepositoryType(repo *github.Repository) (string, error) {
+	if repo.GetDisabled() {
+		return RepositoryTypeDisabled, nil
+	}
+
+	if repo.GetSize() == 0 {
+		empty, err := m.RepositoryEmpty(repo.GetOwner().GetLogin(), repo.GetName(), repo.GetDefaultBranch())
+		if err != nil {
+			return "", err
+		}
+		if empty {
+			return RepositoryTypeEmpty, nil
+		}
+	}
+
+	if repo.GetFork() && m.Policy.RepositoryTypes.ForkAction() == RepositoryTypeSkip {
+		return RepositoryTypeFork, nil
+	}
+
+	if repo.GetIsTemplate() && m.Policy.RepositoryTypes.TemplateAction() == RepositoryTypeSkip {
+		return RepositoryTypeTemplate, nil
+	}
+
+	return "", nil
+}
+
+func defaultRepositoryTypeAction(action, defaultAction string) string {
+	if action == "" {
+		return defaultAction
+	}
+
+	return strings.ToLower(action)
+}
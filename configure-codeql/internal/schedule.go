@@ -0,0 +1,210 @@
+package internal
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v52/github"
+)
+
+type Scheduler struct {
+	mutex sync.Mutex
+	load  map[int]int
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		load: map[int]int{},
+	}
+}
+
+func (s *Scheduler) Observe(cron string) {
+	slots, ok := ParseWeeklyCronSlots(cron)
+	if !ok {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, slot := range slots {
+		s.load[slot]++
+	}
+}
+
+func (s *Scheduler) Assign(repositoryID int64, windows []ScheduleWindow, previous []string) (string, bool) {
+	allowed := WindowSlots(windows)
+
+	for _, cron := range previous {
+		slots, ok := ParseWeeklyCronSlots(cron)
+		if ok && containsAllSlots(allowed, slots) {
+			return cron, true
+		}
+	}
+
+	hash := fnv.New64a()
+	hash.Write([]byte(strconv.FormatInt(repositoryID, 10)))
+	sum := hash.Sum64()
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	maximum := 0
+	for _, slot := range allowed {
+		if s.load[slot] > maximum {
+			maximum = s.load[slot]
+		}
+	}
+	var total uint64
+	for _, slot := range allowed {
+		total += uint64(maximum - s.load[slot] + 1)
+	}
+
+	point := sum % total
+	selected := allowed[len(allowed)-1]
+	for _, slot := range allowed {
+		weight := uint64(maximum - s.load[slot] + 1)
+		if point < weight {
+			selected = slot
+			break
+		}
+		point -= weight
+	}
+
+	minute := int((sum / total) % 60)
+	return fmt.Sprintf("%d %d * * %d", minute, selected%24, selected/24), false
+}
+
+func (m *Manager) ObserveSchedules(repo *github.Repository) {
+	if repo.GetArchived() || repo.GetDisabled() || repo.GetSize() == 0 {
+		return
+	}
+	logger := m.GlobalLogger.WithField("repo", repo.GetName())
+
+	analysis, err := m.AnalyzeWorkflows(repo.GetOwner().GetLogin(), repo.GetName(), repo.GetDefaultBranch())
+	if err != nil {
+		logger.Warnf("Failed to analyze workflows, existing schedules not counted: %v", err)
+		return
+	}
+	for _, workflowFile := range analysis.Workflows {
+		if workflowFile.Has(JobDirect) || workflowFile.Has(JobReusable) {
+			for _, cron := range workflowFile.Crons {
+				m.Scheduler.Observe(cron)
+			}
+		}
+	}
+}
+
+func WindowSlots(windows []ScheduleWindow) []int {
+	if len(windows) == 0 {
+		windows = []ScheduleWindow{
+			{
+				StartHour: 0,
+				EndHour:   24,
+			},
+		}
+	}
+
+	seen := map[int]bool{}
+	var slots []int
+	for _, window := range windows {
+		for _, day := range window.Weekdays() {
+			for hour := window.StartHour; hour < window.EndHour; hour++ {
+				slot := day*24 + hour
+				if !seen[slot] {
+					seen[slot] = true
+					slots = append(slots, slot)
+				}
+			}
+		}
+	}
+	sort.Ints(slots)
+
+	return slots
+}
+
+func ParseWeeklyCronSlots(cron string) ([]int, bool) {
+	fields := strings.Fields(cron)
+	if len(fields) != 5 || fields[2] != "*" || fields[3] != "*" {
+		return nil, false
+	}
+
+	hours, ok := parseCronField(fields[1], 0, 23)
+	if !ok {
+		return nil, false
+	}
+	days, ok := parseCronField(fields[4], 0, 7)
+	if !ok {
+		return nil, false
+	}
+
+	seen := map[int]bool{}
+	var slots []int
+	for _, day := range days {
+		for _, hour := range hours {
+			slot := (day%7)*24 + hour
+			if !seen[slot] {
+				seen[slot] = true
+				slots = append(slots, slot)
+			}
+		}
+	}
+
+	return slots, true
+}
+
+func parseCronField(field string, minimum, maximum int) ([]int, bool) {
+	var values []int
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if index := strings.Index(part, "/"); index != -1 {
+			var err error
+			step, err = strconv.Atoi(part[index+1:])
+			if err != nil || step < 1 {
+				return nil, false
+			}
+			part = part[:index]
+		}
+
+		start, end := minimum, maximum
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			start, err = strconv.Atoi(bounds[0])
+			if err != nil {
+				return nil, false
+			}
+			end = start
+			if len(bounds) == 2 {
+				end, err = strconv.Atoi(bounds[1])
+				if err != nil {
+					return nil, false
+				}
+			}
+		}
+		if start < minimum || end > maximum || start > end {
+			return nil, false
+		}
+
+		for value := start; value <= end; value += step {
+			values = append(values, value)
+		}
+	}
+
+	return values, true
+}
+
+func containsAllSlots(allowed, slots []int) bool {
+	for _, slot := range slots {
+		index := sort.SearchInts(allowed, slot)
+		if index == len(allowed) || allowed[index] != slot {
+			return false
+		}
+	}
+
+	return true
+}
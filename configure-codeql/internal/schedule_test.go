@@ -0,0 +1,187 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseWeeklyCronSlots(t *testing.T) {
+	tests := []struct {
+		name  string
+		cron  string
+		slots []int
+		ok    bool
+	}{
+		{name: "single slot", cron: "15 3 * * 2", slots: []int{51}, ok: true},
+		{name: "sunday as zero", cron: "0 2 * * 0", slots: []int{2}, ok: true},
+		{name: "sunday as seven", cron: "0 2 * * 7", slots: []int{2}, ok: true},
+		{name: "sunday listed twice", cron: "0 2 * * 0,7", slots: []int{2}, ok: true},
+		{name: "hour step", cron: "0 */6 * * 1", slots: []int{24, 30, 36, 42}, ok: true},
+		{name: "hour range with step", cron: "0 2-6/2 * * 1", slots: []int{26, 28, 30}, ok: true},
+		{name: "day range", cron: "0 1 * * 5-7", slots: []int{121, 145, 1}, ok: true},
+		{name: "hour list", cron: "0 1,23 * * 6", slots: []int{145, 167}, ok: true},
+		{name: "every day", cron: "0 0 * * *", slots: []int{0, 24, 48, 72, 96, 120, 144}, ok: true},
+		{name: "day of month", cron: "0 2 1 * *", ok: false},
+		{name: "month", cron: "0 2 * 1 *", ok: false},
+		{name: "hour out of range", cron: "0 24 * * 1", ok: false},
+		{name: "day out of range", cron: "0 2 * * 8", ok: false},
+		{name: "reversed range", cron: "0 2 * * 5-3", ok: false},
+		{name: "zero step", cron: "0 */0 * * 1", ok: false},
+		{name: "named day", cron: "0 2 * * mon", ok: false},
+		{name: "too few fields", cron: "0 2 * *", ok: false},
+		{name: "macro", cron: "@weekly", ok: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			slots, ok := ParseWeeklyCronSlots(test.cron)
+			if ok != test.ok {
+				t.Fatalf("got ok %t, want %t", ok, test.ok)
+			}
+			if !reflect.DeepEqual(slots, test.slots) {
+				t.Errorf("got slots %v, want %v", slots, test.slots)
+			}
+		})
+	}
+}
+
+func TestWindowSlots(t *testing.T) {
+	tests := []struct {
+		name    string
+		windows []ScheduleWindow
+		first   int
+		last    int
+		count   int
+	}{
+		{
+			name:  "no windows",
+			first: 0,
+			last:  167,
+			count: 168,
+		},
+		{
+			name:    "start of the week",
+			windows: []ScheduleWindow{{Days: []string{"sun"}, StartHour: 0, EndHour: 1}},
+			first:   0,
+			last:    0,
+			count:   1,
+		},
+		{
+			name:    "end of the week",
+			windows: []ScheduleWindow{{Days: []string{"saturday"}, StartHour: 22, EndHour: 24}},
+			first:   166,
+			last:    167,
+			count:   2,
+		},
+		{
+			name:    "all days",
+			windows: []ScheduleWindow{{StartHour: 20, EndHour: 22}},
+			first:   20,
+			last:    165,
+			count:   14,
+		},
+		{
+			name: "overlapping windows",
+			windows: []ScheduleWindow{
+				{Days: []string{"mon", "tue"}, StartHour: 0, EndHour: 4},
+				{Days: []string{"1"}, StartHour: 2, EndHour: 6},
+			},
+			first: 24,
+			last:  51,
+			count: 10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			slots := WindowSlots(test.windows)
+			if len(slots) != test.count {
+				t.Fatalf("got %d slots, want %d: %v", len(slots), test.count, slots)
+			}
+			if slots[0] != test.first || slots[len(slots)-1] != test.last {
+				t.Errorf("got slots %d-%d, want %d-%d", slots[0], slots[len(slots)-1], test.first, test.last)
+			}
+			for i := 1; i < len(slots); i++ {
+				if slots[i] <= slots[i-1] {
+					t.Fatalf("slots are not sorted and unique: %v", slots)
+				}
+			}
+		})
+	}
+}
+
+func TestSchedulerAssign(t *testing.T) {
+	windows := []ScheduleWindow{{Days: []string{"sat", "sun"}, StartHour: 0, EndHour: 6}}
+	allowed := WindowSlots(windows)
+
+	tests := []struct {
+		name     string
+		previous []string
+		kept     bool
+	}{
+		{name: "new schedule"},
+		{name: "keeps previous schedule inside window", previous: []string{"30 2 * * 6"}, kept: true},
+		{name: "keeps previous schedule on day seven", previous: []string{"30 2 * * 7"}, kept: true},
+		{name: "replaces previous schedule outside window", previous: []string{"30 12 * * 6"}},
+		{name: "replaces invalid previous schedule", previous: []string{"not a cron"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cron, kept := NewScheduler().Assign(42, windows, test.previous)
+			if kept != test.kept {
+				t.Fatalf("got kept %t, want %t", kept, test.kept)
+			}
+			if kept && cron != test.previous[0] {
+				t.Errorf("got cron %s, want %s", cron, test.previous[0])
+			}
+			slots, ok := ParseWeeklyCronSlots(cron)
+			if !ok || !containsAllSlots(allowed, slots) {
+				t.Errorf("cron %s is outside the allowed windows", cron)
+			}
+		})
+	}
+}
+
+func TestSchedulerAssignDeterministic(t *testing.T) {
+	existing := []string{"0 1 * * 6", "0 1 * * 6", "0 2 * * 0"}
+	newScheduler := func() *Scheduler {
+		scheduler := NewScheduler()
+		for _, cron := range existing {
+			scheduler.Observe(cron)
+		}
+		return scheduler
+	}
+
+	forward := newScheduler()
+	crons := map[int64]string{}
+	for id := int64(1); id <= 100; id++ {
+		crons[id], _ = forward.Assign(id, nil, nil)
+	}
+
+	reverse := newScheduler()
+	for id := int64(100); id >= 1; id-- {
+		cron, _ := reverse.Assign(id, nil, nil)
+		if cron != crons[id] {
+			t.Fatalf("repository %d got %s and %s depending on processing order", id, crons[id], cron)
+		}
+	}
+}
+
+func TestSchedulerAssignPrefersLeastLoadedSlots(t *testing.T) {
+	windows := []ScheduleWindow{{Days: []string{"mon"}, StartHour: 1, EndHour: 3}}
+	scheduler := NewScheduler()
+	for i := 0; i < 10; i++ {
+		scheduler.Observe("0 1 * * 1")
+	}
+
+	counts := map[int]int{}
+	for id := int64(0); id < 1000; id++ {
+		cron, _ := scheduler.Assign(id, windows, nil)
+		slots, _ := ParseWeeklyCronSlots(cron)
+		counts[slots[0]]++
+	}
+	if counts[26] <= counts[25]*5 {
+		t.Errorf("expected the unloaded slot to receive most schedules, got %v", counts)
+	}
+}
@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	SecurityFeatureAdvancedSecurity             = "advanced_security"
+	SecurityFeatureDependabotSecurityUpdates    = "dependabot_security_updates"
+	SecurityFeatureSecretScanning               = "secret_scanning"
+	SecurityFeatureSecretScanningPushProtection = "secret_scanning_push_protection"
+
+	SecurityStatusDisabled = "disabled"
+	SecurityStatusEnabled  = "enabled"
+)
+
+var SecurityFeatures = []string{
+	SecurityFeatureAdvancedSecurity,
+	SecurityFeatureSecretScanning,
+	SecurityFeatureSecretScanningPushProtection,
+	SecurityFeatureDependabotSecurityUpdates,
+}
+
+var SecurityFeatureNames = map[string]string{
+	SecurityFeatureAdvancedSecurity:             "GitHub Advanced Security",
+	SecurityFeatureDependabotSecurityUpdates:    "Dependabot security updates",
+	SecurityFeatureSecretScanning:               "Secret scanning",
+	SecurityFeatureSecretScanningPushProtection: "Secret scanning push protection",
+}
+
+func (p *RepositoryPolicy) SecurityFeatures() []string {
+	var features []string
+	for _, feature := range SecurityFeatures {
+		if p.Security[feature] {
+			features = append(features, feature)
+		}
+	}
+
+	return features
+}
+
+func (m *Manager) MissingSecurityFeatures(repo *github.Repository, policy *RepositoryPolicy, logger *log.Entry) ([]string, error) {
+	org := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+
+	features := policy.SecurityFeatures()
+	if len(features) == 0 {
+		return nil, nil
+	}
+
+	securityAndAnalysis := repo.GetSecurityAndAnalysis()
+	if repo.SecurityAndAnalysis == nil {
+		logger.Infof("Retrieving security and analysis settings")
+		var err error
+		securityAndAnalysis, err = m.GetSecurityAndAnalysis(org, name)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debugf("Retrieved security and analysis settings")
+	}
+
+	var missing []string
+	for _, feature := range features {
+		var enabled bool
+		switch feature {
+		case SecurityFeatureAdvancedSecurity:
+			enabled = repo.GetVisibility() == "public" || securityAndAnalysis.GetAdvancedSecurity().GetStatus() == SecurityStatusEnabled
+		case SecurityFeatureSecretScanning:
+			enabled = securityAndAnalysis.GetSecretScanning().GetStatus() == SecurityStatusEnabled
+		case SecurityFeatureSecretScanningPushProtection:
+			enabled = securityAndAnalysis.GetSecretScanningPushProtection().GetStatus() == SecurityStatusEnabled
+		case SecurityFeatureDependabotSecurityUpdates:
+			var err error
+			enabled, err = m.AutomatedSecurityFixesEnabled(org, name)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !enabled {
+			missing = append(missing, feature)
+		}
+	}
+	if len(missing) == 0 {
+		logger.Debugf("Security features already enabled: [%s]", strings.Join(features, ", "))
+	}
+
+	return missing, nil
+}
+
+func (m *Manager) EnableSecurityFeatures(repo *github.Repository, features []string, logger *log.Entry, tx *Transaction) error {
+	org := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+
+	var updated []string
+	for _, feature := range features {
+		if feature != SecurityFeatureDependabotSecurityUpdates {
+			updated = append(updated, feature)
+		}
+	}
+	if len(updated) > 0 {
+		logger.Infof("Updating security and analysis settings")
+		err := m.UpdateSecurityAndAnalysis(org, name, securityAndAnalysisUpdate(updated, SecurityStatusEnabled))
+		if err != nil {
+			return err
+		}
+		logger.Debugf("Updated security and analysis settings")
+	}
+	for _, feature := range features {
+		if feature == SecurityFeatureDependabotSecurityUpdates {
+			logger.Infof("Checking if vulnerability alerts are enabled")
+			alertsEnabled, err := m.VulnerabilityAlertsEnabled(org, name)
+			if err != nil {
+				return err
+			}
+			logger.Debugf("Vulnerability alerts enabled: %t", alertsEnabled)
+
+			if !alertsEnabled {
+				logger.Infof("Enabling vulnerability alerts")
+				err = m.EnableVulnerabilityAlerts(org, name)
+				if err != nil {
+					return err
+				}
+				tx.RecordVulnerabilityAlerts()
+				logger.Debugf("Enabled vulnerability alerts")
+			}
+
+			logger.Infof("Enabling Dependabot security updates")
+			err = m.EnableAutomatedSecurityFixes(org, name)
+			if err != nil {
+				return err
+			}
+		}
+		tx.RecordSecurityFeature(feature)
+		logger.WithFields(log.Fields{
+			"event":               logging.EventEnabledSecurityFeature,
+			logging.DetailFeature: feature,
+		}).Infof("Enabled %s", SecurityFeatureNames[feature])
+	}
+
+	return nil
+}
+
+func (m *Manager) DisableSecurityFeature(owner, repo, feature string) error {
+	if feature == SecurityFeatureDependabotSecurityUpdates {
+		return m.DisableAutomatedSecurityFixes(owner, repo)
+	}
+
+	return m.UpdateSecurityAndAnalysis(owner, repo, securityAndAnalysisUpdate([]string{feature}, SecurityStatusDisabled))
+}
+
+func securityAndAnalysisUpdate(features []string, status string) *github.SecurityAndAnalysis {
+	update := &github.SecurityAndAnalysis{}
+	for _, feature := range features {
+		switch feature {
+		case SecurityFeatureAdvancedSecurity:
+			update.AdvancedSecurity = &github.AdvancedSecurity{Status: github.String(status)}
+		case SecurityFeatureSecretScanning:
+			update.SecretScanning = &github.SecretScanning{Status: github.String(status)}
+		case SecurityFeatureSecretScanningPushProtection:
+			update.SecretScanningPushProtection = &github.SecretScanningPushProtection{Status: github.String(status)}
+		}
+	}
+
+	return update
+}
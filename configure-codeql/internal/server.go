@@ -0,0 +1,221 @@
+package internal
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/google/go-github/v52/github"
+)
+
+const (
+	deliveryHistorySize = 1000
+	emptySHA            = "0000000000000000000000000000000000000000"
+)
+
+type Server struct {
+	Manager *Manager
+	Secret  []byte
+
+	queue      chan string
+	done       chan struct{}
+	mutex      sync.Mutex
+	closed     bool
+	pending    map[string]bool
+	deliveries map[string]bool
+	history    []string
+}
+
+func NewServer(m *Manager, secret []byte, queueSize int) *Server {
+	return &Server{
+		Manager:    m,
+		Secret:     secret,
+		queue:      make(chan string, queueSize),
+		done:       make(chan struct{}),
+		pending:    map[string]bool{},
+		deliveries: map[string]bool{},
+	}
+}
+
+func (s *Server) Start() {
+	go s.work()
+}
+
+func (s *Server) Shutdown() {
+	s.mutex.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.queue)
+	}
+	s.mutex.Unlock()
+
+	<-s.done
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	logger := s.Manager.GlobalLogger
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.Header.Get(github.SHA256SignatureHeader) == "" {
+		logger.Warnf("Rejecting webhook delivery without a %s header", github.SHA256SignatureHeader)
+		http.Error(w, "missing signature", http.StatusUnauthorized)
+		return
+	}
+	payload, err := github.ValidatePayload(r, s.Secret)
+	if err != nil {
+		logger.Warnf("Rejecting webhook delivery with an invalid signature: %v", err)
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	eventType := github.WebHookType(r)
+	delivery := github.DeliveryID(r)
+	event, err := github.ParseWebHook(eventType, payload)
+	if err != nil {
+		logger.Warnf("Failed to parse '%s' webhook delivery %s: %v", eventType, delivery, err)
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	if s.delivered(delivery) {
+		logger.Debugf("Ignoring duplicate webhook delivery %s", delivery)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	repos := WebhookRepositories(event)
+	if len(repos) == 0 {
+		logger.Debugf("Ignoring '%s' webhook delivery %s", eventType, delivery)
+		s.recordDelivery(delivery)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	for _, repo := range repos {
+		err = s.Enqueue(repo)
+		if err != nil {
+			logger.Errorf("failed to queue repository %s from webhook delivery %s: %v", repo, delivery, err)
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+	}
+	s.recordDelivery(delivery)
+	logger.Infof("Queued %d repositories from '%s' webhook delivery %s", len(repos), eventType, delivery)
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) Enqueue(fullName string) error {
+	fullName = strings.ToLower(fullName)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.closed {
+		return fmt.Errorf("server is shutting down")
+	}
+	if s.pending[fullName] {
+		s.Manager.GlobalLogger.Debugf("Repository %s is already queued", fullName)
+		return nil
+	}
+	select {
+	case s.queue <- fullName:
+		s.pending[fullName] = true
+		return nil
+	default:
+		return fmt.Errorf("queue is full")
+	}
+}
+
+func (s *Server) delivered(delivery string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return delivery != "" && s.deliveries[delivery]
+}
+
+func (s *Server) recordDelivery(delivery string) {
+	if delivery == "" {
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.deliveries[delivery] {
+		return
+	}
+	s.deliveries[delivery] = true
+	s.history = append(s.history, delivery)
+	if len(s.history) > deliveryHistorySize {
+		delete(s.deliveries, s.history[0])
+		s.history = s.history[1:]
+	}
+}
+
+func (s *Server) work() {
+	defer close(s.done)
+
+	for fullName := range s.queue {
+		s.mutex.Lock()
+		delete(s.pending, fullName)
+		s.mutex.Unlock()
+
+		s.process(fullName)
+	}
+}
+
+func (s *Server) process(fullName string) {
+	m := s.Manager
+	owner, name, _ := strings.Cut(fullName, "/")
+	logger := m.GlobalLogger.WithField("repo", name)
+
+	logger.Infof("Retrieving repository")
+	repo, _, err := m.AdminGitHubClient.Repositories.Get(m.Context, owner, name)
+	if err != nil {
+		logger.Errorf("failed to retrieve repository, skipping repo: %v", err)
+		return
+	}
+	logger.Debugf("Repository retrieved")
+
+	logger.Infof("Checking if verify-scans app is installed")
+	installed, err := m.VerifyScansAppInstalled(owner, repo.GetName())
+	if err != nil {
+		logger.Errorf("failed to check verify-scans app installation, skipping repo: %v", err)
+		return
+	}
+	m.SetVerifyScansInstalled(repo.GetName(), installed)
+	logger.Debugf("Verify-scans app installed: %t", installed)
+
+	m.ProcessRepository(repo)
+}
+
+func WebhookRepositories(event interface{}) []string {
+	var repos []string
+	switch e := event.(type) {
+	case *github.RepositoryEvent:
+		if e.GetAction() == "created" || e.GetAction() == "unarchived" {
+			repos = append(repos, e.GetRepo().GetFullName())
+		}
+	case *github.InstallationRepositoriesEvent:
+		if e.GetAction() == "added" {
+			for _, repo := range e.RepositoriesAdded {
+				repos = append(repos, repo.GetFullName())
+			}
+		}
+	case *github.PushEvent:
+		defaultRef := fmt.Sprintf("refs/heads/%s", e.GetRepo().GetDefaultBranch())
+		if e.GetCreated() && e.GetBefore() == emptySHA && e.GetRef() == defaultRef {
+			repos = append(repos, e.GetRepo().GetFullName())
+		}
+	case *github.PullRequestEvent:
+		if e.GetAction() == "closed" && e.GetPullRequest().GetMerged() && IsEnablementPullRequest(e.GetPullRequest()) {
+			repos = append(repos, e.GetRepo().GetFullName())
+		}
+	}
+
+	return repos
+}
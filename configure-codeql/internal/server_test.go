@@ -0,0 +1,183 @@
+package internal
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const testWebhookSecret = "webhook-secret"
+
+func readWebhookPayload(t *testing.T, name string) []byte {
+	t.Helper()
+
+	payload, err := os.ReadFile(filepath.Join("testdata", "webhooks", name))
+	if err != nil {
+		t.Fatalf("failed to read payload: %v", err)
+	}
+
+	return payload
+}
+
+func newTestServer() *Server {
+	logger := log.New()
+	logger.SetOutput(io.Discard)
+
+	return NewServer(&Manager{GlobalLogger: logger}, []byte(testWebhookSecret), 10)
+}
+
+func newWebhookRequest(eventType, delivery string, payload []byte, secret string) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewReader(payload))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set(github.EventTypeHeader, eventType)
+	r.Header.Set(github.DeliveryIDHeader, delivery)
+	if secret != "" {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		r.Header.Set(github.SHA256SignatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	return r
+}
+
+func TestWebhookRepositories(t *testing.T) {
+	tests := []struct {
+		name      string
+		eventType string
+		payload   string
+		repos     []string
+	}{
+		{
+			name:      "repository created",
+			eventType: "repository",
+			payload:   "repository-created.json",
+			repos:     []string{"department-of-veterans-affairs/claims-api"},
+		},
+		{
+			name:      "repository renamed",
+			eventType: "repository",
+			payload:   "repository-renamed.json",
+		},
+		{
+			name:      "installation repositories added",
+			eventType: "installation_repositories",
+			payload:   "installation_repositories-added.json",
+			repos:     []string{"department-of-veterans-affairs/benefits-portal", "department-of-veterans-affairs/Forms-Service"},
+		},
+		{
+			name:      "default branch created",
+			eventType: "push",
+			payload:   "push-default-branch-created.json",
+			repos:     []string{"department-of-veterans-affairs/claims-api"},
+		},
+		{
+			name:      "feature branch created",
+			eventType: "push",
+			payload:   "push-feature-branch-created.json",
+		},
+		{
+			name:      "enablement pull request merged",
+			eventType: "pull_request",
+			payload:   "pull_request-closed-merged.json",
+			repos:     []string{"department-of-veterans-affairs/claims-api"},
+		},
+		{
+			name:      "enablement pull request on a prefixed branch merged",
+			eventType: "pull_request",
+			payload:   "pull_request-closed-merged-prefixed-branch.json",
+			repos:     []string{"department-of-veterans-affairs/claims-api"},
+		},
+		{
+			name:      "other pull request merged",
+			eventType: "pull_request",
+			payload:   "pull_request-closed-merged-other-branch.json",
+		},
+		{
+			name:      "enablement pull request closed without merging",
+			eventType: "pull_request",
+			payload:   "pull_request-closed-unmerged.json",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event, err := github.ParseWebHook(test.eventType, readWebhookPayload(t, test.payload))
+			if err != nil {
+				t.Fatalf("failed to parse payload: %v", err)
+			}
+
+			repos := WebhookRepositories(event)
+			if !reflect.DeepEqual(repos, test.repos) {
+				t.Errorf("got repos %v, want %v", repos, test.repos)
+			}
+		})
+	}
+}
+
+func TestServerRejectsInvalidSignatures(t *testing.T) {
+	payload := readWebhookPayload(t, "repository-created.json")
+
+	tests := []struct {
+		name   string
+		secret string
+	}{
+		{name: "missing signature"},
+		{name: "wrong secret", secret: "other-secret"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := newTestServer()
+			w := httptest.NewRecorder()
+			server.ServeHTTP(w, newWebhookRequest("repository", "delivery-1", payload, test.secret))
+
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("got status %d, want %d", w.Code, http.StatusUnauthorized)
+			}
+			if len(server.queue) != 0 {
+				t.Errorf("got %d queued repositories, want none", len(server.queue))
+			}
+		})
+	}
+}
+
+func TestServerIgnoresDuplicateDeliveries(t *testing.T) {
+	server := newTestServer()
+	payload := readWebhookPayload(t, "repository-created.json")
+
+	w := httptest.NewRecorder()
+	server.ServeHTTP(w, newWebhookRequest("repository", "delivery-1", payload, testWebhookSecret))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusAccepted)
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, newWebhookRequest("repository", "delivery-1", payload, testWebhookSecret))
+	if w.Code != http.StatusOK {
+		t.Errorf("got status %d for redelivery, want %d", w.Code, http.StatusOK)
+	}
+
+	w = httptest.NewRecorder()
+	server.ServeHTTP(w, newWebhookRequest("push", "delivery-2", readWebhookPayload(t, "push-default-branch-created.json"), testWebhookSecret))
+	if w.Code != http.StatusAccepted {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusAccepted)
+	}
+
+	if len(server.queue) != 1 {
+		t.Fatalf("got %d queued repositories, want 1", len(server.queue))
+	}
+	if fullName := <-server.queue; fullName != "department-of-veterans-affairs/claims-api" {
+		t.Errorf("got queued repository %s", fullName)
+	}
+}
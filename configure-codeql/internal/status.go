@@ -0,0 +1,339 @@
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	StatusArchived                 = "archived"
+	StatusDisabled                 = RepositoryTypeDisabled
+	StatusEmpty                    = RepositoryTypeEmpty
+	StatusError                    = "error"
+	StatusExempt                   = "exempt"
+	StatusFork                     = RepositoryTypeFork
+	StatusIgnored                  = "ignored"
+	StatusNoSupportedLanguages     = "no-supported-languages"
+	StatusNotConfigured            = "not-configured"
+	StatusPullRequestClosed        = "pr-closed-unmerged"
+	StatusPullRequestMerged        = "pr-merged-awaiting-analysis"
+	StatusPullRequestOpen          = "pr-open"
+	StatusScanningDefaultSetup     = "scanning-default-setup"
+	StatusScanningDirectAction     = "scanning-direct-action"
+	StatusScanningReusableWorkflow = "scanning-reusable-workflow"
+	StatusScanningThirdParty       = "scanning-third-party"
+	StatusTemplate                 = RepositoryTypeTemplate
+)
+
+var StatusStates = []string{
+	StatusDisabled,
+	StatusEmpty,
+	StatusFork,
+	StatusTemplate,
+	StatusIgnored,
+	StatusExempt,
+	StatusArchived,
+	StatusNoSupportedLanguages,
+	StatusNotConfigured,
+	StatusPullRequestOpen,
+	StatusPullRequestClosed,
+	StatusPullRequestMerged,
+	StatusScanningReusableWorkflow,
+	StatusScanningDefaultSetup,
+	StatusScanningDirectAction,
+	StatusScanningThirdParty,
+	StatusError,
+}
+
+type RepositoryStatus struct {
+	Repository         string   `json:"repository"`
+	State              string   `json:"state"`
+	Languages          []string `json:"languages,omitempty"`
+	PullRequest        int      `json:"pull_request,omitempty"`
+	PullRequestAgeDays *int     `json:"pull_request_age_days,omitempty"`
+	Error              string   `json:"error,omitempty"`
+}
+
+type StatusReport struct {
+	Org          string              `json:"org"`
+	GeneratedAt  time.Time           `json:"generated_at"`
+	Repositories int                 `json:"repositories"`
+	Totals       map[string]int      `json:"totals"`
+	Statuses     []*RepositoryStatus `json:"statuses"`
+}
+
+func (m *Manager) StatusRepository(repo *github.Repository) {
+	logger := m.GlobalLogger.WithField("repo", repo.GetName())
+
+	org := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+
+	if org != m.Config.Org {
+		logger.Debugf("skipping repo %s, not in org %s", repo.GetFullName(), m.Config.Org)
+		return
+	}
+
+	status := &RepositoryStatus{
+		Repository: name,
+		State:      StatusError,
+	}
+	defer m.addStatus(status)
+
+	err := m.classifyRepository(repo, status, logger)
+	if err != nil {
+		status.State = StatusError
+		status.Error = err.Error()
+		logger.Errorf("failed to classify repository, skipping repo: %v", err)
+		return
+	}
+	logger.Infof("Repository is in state '%s'", status.State)
+}
+
+func (m *Manager) classifyRepository(repo *github.Repository, status *RepositoryStatus, logger *log.Entry) error {
+	org := repo.GetOwner().GetLogin()
+	name := repo.GetName()
+	defaultBranch := repo.GetDefaultBranch()
+
+	logger.Infof("Classifying repository type")
+	repositoryType, err := m.SkippedRepositoryType(repo)
+	if err != nil {
+		return fmt.Errorf("failed to classify repository type: %v", err)
+	}
+	if repositoryType != "" {
+		status.State = repositoryType
+		return nil
+	}
+
+	logger.Info("Checking if repository is ignored")
+	repoIgnored, err := m.FileExists(org, name, ".github/.emass-repo-ignore")
+	if err != nil {
+		return fmt.Errorf("failed to check if repository is ignored: %v", err)
+	}
+	if repoIgnored {
+		status.State = StatusIgnored
+		return nil
+	}
+
+	if m.Exemptions != nil {
+		logger.Infof("Checking if repository has an approved exemption")
+		exempt, err := m.Exemptions.Exempt(org, name, logger)
+		if err != nil {
+			return fmt.Errorf("failed to check if repository has an approved exemption: %v", err)
+		}
+		if exempt {
+			status.State = StatusExempt
+			return nil
+		}
+	}
+
+	if repo.GetArchived() {
+		status.State = StatusArchived
+		return nil
+	}
+
+	logger.Infof("Retrieving supported languages")
+	languages, err := m.ListSupportedLanguages(org, name)
+	if err != nil {
+		return fmt.Errorf("failed to retrieve supported languages: %v", err)
+	}
+	if len(languages) == 0 {
+		status.State = StatusNoSupportedLanguages
+		return nil
+	}
+	sort.Strings(languages)
+	status.Languages = languages
+	logger.Debugf("Retrieved %d supported languages", len(languages))
+
+	logger.Infof("Retrieving all '%s' pull requests", PullRequestTitle)
+	pullRequests, err := m.ListEnablementPullRequests(org, name, "all")
+	if err != nil {
+		return fmt.Errorf("failed to retrieve pull requests: %v", err)
+	}
+	logger.Debugf("Retrieved %d pull requests", len(pullRequests))
+
+	var openPullRequests []*github.PullRequest
+	for _, pullRequest := range pullRequests {
+		if pullRequest.GetState() == "open" {
+			openPullRequests = append(openPullRequests, pullRequest)
+		}
+	}
+	if len(openPullRequests) > 0 {
+		pullRequest, _ := SelectEnablementPullRequest(openPullRequests)
+		status.State = StatusPullRequestOpen
+		status.PullRequest = pullRequest.GetNumber()
+		age := daysSince(pullRequest.GetCreatedAt().Time)
+		status.PullRequestAgeDays = &age
+		return nil
+	}
+
+	latest := LatestPullRequest(pullRequests)
+	if latest != nil {
+		status.PullRequest = latest.GetNumber()
+	}
+	if latest != nil && latest.MergedAt != nil {
+		logger.Infof("Checking for 'ois-*' analyses on branch %s since pull request #%d was merged", defaultBranch, latest.GetNumber())
+		categories, err := m.ListOISAnalysisCategories(org, name, defaultBranch, latest.GetMergedAt().Time)
+		if err != nil {
+			return fmt.Errorf("failed to list code scanning analyses: %v", err)
+		}
+		if len(categories) == 0 {
+			status.State = StatusPullRequestMerged
+			age := daysSince(latest.GetMergedAt().Time)
+			status.PullRequestAgeDays = &age
+			return nil
+		}
+		logger.Debugf("Found analyses for categories: [%s]", strings.Join(categories, ", "))
+	}
+
+	logger.Infof("Checking if repository has Code Scanning enabled")
+	enabled, workflows, err := m.CodeScanningEnabled(org, name)
+	if err != nil {
+		return fmt.Errorf("failed to check if repository has Code Scanning enabled: %v", err)
+	}
+	if enabled {
+		logger.Infof("Analyzing workflows on branch %s", defaultBranch)
+		workflowAnalysis, err := m.AnalyzeWorkflows(org, name, defaultBranch)
+		if err != nil {
+			return fmt.Errorf("failed to analyze workflows: %v", err)
+		}
+		if len(workflowAnalysis.ReusableWorkflows()) > 0 {
+			status.State = StatusScanningReusableWorkflow
+			return nil
+		}
+
+		defaultScanningEnabled, err := m.DefaultCodeScanningEnabled(org, name)
+		if err != nil {
+			return fmt.Errorf("failed to check if repository is using default code scanning: %v", err)
+		}
+		if defaultScanningEnabled {
+			status.State = StatusScanningDefaultSetup
+			return nil
+		}
+
+		if len(workflowAnalysis.DirectWorkflows()) > 0 {
+			status.State = StatusScanningDirectAction
+			return nil
+		}
+
+		logger.Debugf("Code scanning analyses are not uploaded by a CodeQL job: [%s]", strings.Join(workflows, ", "))
+		status.State = StatusScanningThirdParty
+		return nil
+	}
+	logger.Debugf("Code scanning is not enabled")
+
+	if latest != nil && latest.MergedAt == nil {
+		status.State = StatusPullRequestClosed
+		return nil
+	}
+	status.State = StatusNotConfigured
+
+	return nil
+}
+
+func (m *Manager) addStatus(status *RepositoryStatus) {
+	m.statusesMutex.Lock()
+	defer m.statusesMutex.Unlock()
+
+	m.Statuses = append(m.Statuses, status)
+}
+
+func NewStatusReport(org string, statuses []*RepositoryStatus) *StatusReport {
+	sorted := append([]*RepositoryStatus{}, statuses...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Repository < sorted[j].Repository
+	})
+
+	totals := map[string]int{}
+	for _, state := range StatusStates {
+		totals[state] = 0
+	}
+	for _, status := range sorted {
+		totals[status.State]++
+	}
+
+	return &StatusReport{
+		Org:          org,
+		GeneratedAt:  time.Now().UTC(),
+		Repositories: len(sorted),
+		Totals:       totals,
+		Statuses:     sorted,
+	}
+}
+
+func (r *StatusReport) Log(logger *log.Logger) {
+	logger.Infof("Classified %d repositories", r.Repositories)
+	for _, state := range StatusStates {
+		logger.Infof("%s: %d", state, r.Totals[state])
+	}
+}
+
+func (r *StatusReport) Write(path string) error {
+	reportBytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal status: %v", err)
+	}
+
+	err = os.WriteFile(path, reportBytes, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write status: %v", err)
+	}
+
+	var records [][]string
+	for _, status := range r.Statuses {
+		pullRequest := ""
+		if status.PullRequest != 0 {
+			pullRequest = strconv.Itoa(status.PullRequest)
+		}
+		age := ""
+		if status.PullRequestAgeDays != nil {
+			age = strconv.Itoa(*status.PullRequestAgeDays)
+		}
+		records = append(records, []string{r.Org, status.Repository, status.State, strings.Join(status.Languages, ";"), pullRequest, age, status.Error})
+	}
+	err = writeCSV(StatusCSVPath(path, ""), []string{"org", "repository", "state", "languages", "pull_request", "pull_request_age_days", "error"}, records)
+	if err != nil {
+		return err
+	}
+
+	var totals [][]string
+	for _, state := range StatusStates {
+		totals = append(totals, []string{r.Org, state, strconv.Itoa(r.Totals[state])})
+	}
+
+	return writeCSV(StatusCSVPath(path, "totals"), []string{"org", "state", "repositories"}, totals)
+}
+
+func StatusCSVPath(path, suffix string) string {
+	path = strings.TrimSuffix(path, filepath.Ext(path))
+	if suffix != "" {
+		path = fmt.Sprintf("%s-%s", path, suffix)
+	}
+
+	return path + ".csv"
+}
+
+func writeCSV(path string, header []string, records [][]string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	defer file.Close()
+
+	writer := csv.NewWriter(file)
+	err = writer.WriteAll(append([][]string{header}, records...))
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,157 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	mutationPullRequest       = "pull-request"
+	mutationPullRequestUpdate = "pull-request-update"
+	mutationRef               = "ref"
+	mutationRefUpdate         = "ref-update"
+	mutationSecurity          = "security-feature"
+	mutationAlerts            = "vulnerability-alerts"
+)
+
+type Transaction struct {
+	manager *Manager
+	logger  *log.Entry
+
+	owner string
+	repo  string
+
+	mutations []mutation
+	committed bool
+}
+
+type mutation struct {
+	kind          string
+	branch        string
+	previousSHA   string
+	number        int
+	previousTitle string
+	previousBody  string
+	feature       string
+}
+
+func (m *Manager) NewTransaction(owner, repo string, logger *log.Entry) *Transaction {
+	return &Transaction{
+		manager: m,
+		logger:  logger,
+		owner:   owner,
+		repo:    repo,
+	}
+}
+
+func (t *Transaction) RecordRef(branch string) {
+	t.mutations = append(t.mutations, mutation{
+		kind:   mutationRef,
+		branch: branch,
+	})
+}
+
+func (t *Transaction) RecordRefUpdate(branch, previousSHA string) {
+	t.mutations = append(t.mutations, mutation{
+		kind:        mutationRefUpdate,
+		branch:      branch,
+		previousSHA: previousSHA,
+	})
+}
+
+func (t *Transaction) RecordPullRequest(number int) {
+	t.mutations = append(t.mutations, mutation{
+		kind:   mutationPullRequest,
+		number: number,
+	})
+}
+
+func (t *Transaction) RecordPullRequestUpdate(number int, previousTitle, previousBody string) {
+	t.mutations = append(t.mutations, mutation{
+		kind:          mutationPullRequestUpdate,
+		number:        number,
+		previousTitle: previousTitle,
+		previousBody:  previousBody,
+	})
+}
+
+func (t *Transaction) RecordSecurityFeature(feature string) {
+	t.mutations = append(t.mutations, mutation{
+		kind:    mutationSecurity,
+		feature: feature,
+	})
+}
+
+func (t *Transaction) RecordVulnerabilityAlerts() {
+	t.mutations = append(t.mutations, mutation{
+		kind: mutationAlerts,
+	})
+}
+
+func (t *Transaction) Commit() {
+	t.committed = true
+}
+
+func (t *Transaction) Rollback() {
+	if t.committed || len(t.mutations) == 0 {
+		return
+	}
+
+	t.logger.Warnf("Rolling back %d changes", len(t.mutations))
+	failed := 0
+	for i := len(t.mutations) - 1; i >= 0; i-- {
+		err := t.undo(t.mutations[i])
+		if err != nil {
+			failed++
+			t.logger.WithField("event", logging.EventRollbackFailed).Errorf("Failed to roll back %s: %v", t.mutations[i], err)
+			continue
+		}
+		t.logger.Debugf("Rolled back %s", t.mutations[i])
+	}
+	t.mutations = nil
+
+	if failed > 0 {
+		return
+	}
+	t.logger.WithField("event", logging.EventRolledBack).Infof("Successfully rolled back repository changes")
+}
+
+func (t *Transaction) undo(mut mutation) error {
+	switch mut.kind {
+	case mutationPullRequest:
+		return t.manager.ClosePullRequest(t.owner, t.repo, mut.number)
+	case mutationPullRequestUpdate:
+		return t.manager.UpdatePullRequest(t.owner, t.repo, mut.number, mut.previousTitle, mut.previousBody)
+	case mutationRef:
+		return t.manager.DeleteRef(t.owner, t.repo, mut.branch)
+	case mutationRefUpdate:
+		return t.manager.UpdateRef(t.owner, t.repo, mut.branch, mut.previousSHA, true)
+	case mutationSecurity:
+		return t.manager.DisableSecurityFeature(t.owner, t.repo, mut.feature)
+	case mutationAlerts:
+		return t.manager.DisableVulnerabilityAlerts(t.owner, t.repo)
+	}
+
+	return fmt.Errorf("unknown mutation type %s", mut.kind)
+}
+
+func (mut mutation) String() string {
+	switch mut.kind {
+	case mutationPullRequest:
+		return fmt.Sprintf("pull request #%d", mut.number)
+	case mutationPullRequestUpdate:
+		return fmt.Sprintf("update of pull request #%d", mut.number)
+	case mutationRef:
+		return fmt.Sprintf("branch %s", mut.branch)
+	case mutationRefUpdate:
+		return fmt.Sprintf("update of branch %s from %s", mut.branch, mut.previousSHA)
+	case mutationSecurity:
+		return fmt.Sprintf("%s setting", SecurityFeatureNames[mut.feature])
+	case mutationAlerts:
+		return "vulnerability alerts setting"
+	}
+
+	return mut.kind
+}
@@ -1,22 +1,63 @@
 package internal
 
+import (
+	"gopkg.in/yaml.v3"
+)
+
 type Input struct {
 	AdminToken                    string
+	CommitAuthorEmail             string
+	CommitAuthorName              string
+	CommitMessage                 string
+	Concurrency                   int
+	EMASSDocumentationURL         string
+	EMASSInventoryPath            string
+	EMASSInventoryRepo            string
+	Enterprise                    bool
+	EscalationThresholdDays       int
+	EventsPath                    string
+	ExemptionProperty             string
+	GmailFrom                     string
+	GmailPassword                 string
+	Mode                          string
 	ConfigureCodeQLAppID          int64
 	ConfigureCodeQLPrivateKey     []byte
 	ConfigureCodeQLInstallationID int64
 	Org                           string
+	OrgOverrides                  map[string]map[string]string
+	PlanMode                      bool
+	PlanPath                      string
+	PolicyPath                    string
+	PolicyRepo                    string
 	PullRequestBody               string
+	ReminderIntervalDays          int
 	Repo                          string
+	ReportPath                    string
+	StatusPath                    string
 	VerifyScansAppID              int64
 	VerifyScansPrivateKey         []byte
 	VerifyScansInstallationID     int64
 }
 
+type ServerInput struct {
+	ListenAddress string
+	QueueSize     int
+	WebhookSecret []byte
+}
+
+type GeneratedFile struct {
+	Path    string
+	Content string
+}
+
 type DefaultCodeScanning struct {
 	State string `json:"state"`
 }
 
+type AutomatedSecurityFixes struct {
+	Enabled bool `json:"enabled"`
+}
+
 type AnalysisTemplate struct {
 	Name string `yaml:"name"`
 	On   On     `yaml:"on"`
@@ -44,7 +85,7 @@ type Jobs struct {
 
 type Job struct {
 	Name        string            `yaml:"name"`
-	RunsOn      string            `yaml:"runs-on"`
+	RunsOn      interface{}       `yaml:"runs-on"`
 	Concurrency string            `yaml:"concurrency"`
 	Permissions map[string]string `yaml:"permissions"`
 	Strategy    Strategy          `yaml:"strategy"`
@@ -57,7 +98,15 @@ type Strategy struct {
 }
 
 type Matrix struct {
-	Language []string `yaml:"language"`
+	Language []string        `yaml:"language,omitempty"`
+	Include  []MatrixInclude `yaml:"include,omitempty"`
+}
+
+type MatrixInclude struct {
+	Language      string       `yaml:"language"`
+	RunsOn        RunnerLabels `yaml:"runs-on"`
+	BuildStepName string       `yaml:"build_step_name"`
+	Path          string       `yaml:"path"`
 }
 
 type Step struct {
@@ -66,6 +115,20 @@ type Step struct {
 	With map[string]string `yaml:"with"`
 }
 
+type workflowDefinition struct {
+	On   yaml.Node              `yaml:"on"`
+	Jobs map[string]workflowJob `yaml:"jobs"`
+}
+
+type workflowJob struct {
+	Uses  string         `yaml:"uses"`
+	Steps []workflowStep `yaml:"steps"`
+}
+
+type workflowStep struct {
+	Uses string `yaml:"uses"`
+}
+
 type EMASS struct {
 	SystemID         int64  `json:"systemID"`
 	SystemName       string `json:"systemName"`
@@ -3,39 +3,99 @@ package internal
 import (
 	"encoding/json"
 	"fmt"
-	"math/rand"
 	"strings"
 
+	"github.com/google/go-github/v52/github"
 	"gopkg.in/yaml.v3"
 )
 
-func GenerateRandomSuffix(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyz0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		b[i] = charset[rand.Intn(len(charset))]
+func IsEnablementPullRequest(pullRequest *github.PullRequest) bool {
+	if pullRequest.GetHead().GetRepo().GetID() != pullRequest.GetBase().GetRepo().GetID() {
+		return false
 	}
 
-	return string(b)
+	return strings.HasPrefix(pullRequest.GetHead().GetRef(), SourceBranchName) || strings.Contains(pullRequest.GetBody(), PullRequestMarker)
 }
 
-func GeneratePullRequestBody(template, owner, repo, branch string, languages []string) string {
-	return fmt.Sprintf("This pull request adds a CodeQL workflow to your repository. This workflow will analyze your code for vulnerabilities in the following languages: %s", strings.Join(languages, ", "))
+func SelectEnablementPullRequest(pullRequests []*github.PullRequest) (*github.PullRequest, []*github.PullRequest) {
+	if len(pullRequests) == 0 {
+		return nil, nil
+	}
+
+	selected := 0
+	for i, pullRequest := range pullRequests {
+		if pullRequest.GetHead().GetRef() == SourceBranchName {
+			selected = i
+			break
+		}
+	}
+
+	var duplicates []*github.PullRequest
+	for i, pullRequest := range pullRequests {
+		if i != selected {
+			duplicates = append(duplicates, pullRequest)
+		}
+	}
+
+	return pullRequests[selected], duplicates
+}
+
+func LatestPullRequest(pullRequests []*github.PullRequest) *github.PullRequest {
+	var latest *github.PullRequest
+	for _, pullRequest := range pullRequests {
+		if latest == nil || pullRequest.GetNumber() > latest.GetNumber() {
+			latest = pullRequest
+		}
+	}
+
+	return latest
 }
 
-func GenerateCodeQLWorkflow(languages []string, defaultBranch string) (string, error) {
-	workflow := AnalysisTemplate{
+func GenerateCodeQLWorkflow(languages []string, defaultBranch, cron string, policy *RepositoryPolicy) (string, error) {
+	workflow := NewAnalysisTemplate(languages, defaultBranch, cron, policy)
+	workflowBytes, err := yaml.Marshal(workflow)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal workflow: %w", err)
+	}
+
+	return string(workflowBytes), nil
+}
+
+func NewAnalysisTemplate(languages []string, defaultBranch, cron string, policy *RepositoryPolicy) AnalysisTemplate {
+	branches := []string{defaultBranch}
+	for _, branch := range policy.Branches {
+		if !Contains(branches, branch) {
+			branches = append(branches, branch)
+		}
+	}
+
+	var runsOn interface{} = policy.Runner(DefaultRunnerKey)
+	matrix := Matrix{
+		Language: languages,
+	}
+	step := NewReusableStep(policy, false)
+
+	entries := policy.MatrixEntries(languages)
+	if RequiresMatrixInclude(entries, policy.Runner(DefaultRunnerKey)) {
+		runsOn = "${{ matrix.runs-on }}"
+		matrix = Matrix{
+			Include: entries,
+		}
+		step = NewReusableStep(policy, true)
+	}
+
+	return AnalysisTemplate{
 		Name: "CodeQL",
 		On: On{
 			Push: Branch{
-				Branches: []string{defaultBranch},
+				Branches: branches,
 			},
 			PullRequest: Branch{
-				Branches: []string{defaultBranch},
+				Branches: branches,
 			},
 			Schedule: []Cron{
 				{
-					Cron: GenerateRandomWeeklyCron(),
+					Cron: cron,
 				},
 			},
 			WorkflowDispatch: nil,
@@ -43,7 +103,7 @@ func GenerateCodeQLWorkflow(languages []string, defaultBranch string) (string, e
 		Jobs: Jobs{
 			Analyze: Job{
 				Name:        "Analyze",
-				RunsOn:      "ubuntu-latest",
+				RunsOn:      runsOn,
 				Concurrency: "${{ github.workflow }}-${{ github.ref }}",
 				Permissions: map[string]string{
 					"actions":         "read",
@@ -52,38 +112,65 @@ func GenerateCodeQLWorkflow(languages []string, defaultBranch string) (string, e
 				},
 				Strategy: Strategy{
 					FailFast: false,
-					Matrix: Matrix{
-						Language: languages,
-					},
+					Matrix:   matrix,
 				},
 				Steps: []Step{
-					{
-						Name: "Run Code Scanning",
-						Uses: "department-of-veterans-affairs/codeql-tools/codeql-analysis@main",
-						With: map[string]string{
-							"languages": "${{ matrix.Language }}",
-						},
-					},
+					step,
 				},
 			},
 		},
 	}
+}
 
-	workflowBytes, err := yaml.Marshal(workflow)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal workflow: %w", err)
+func NewReusableStep(policy *RepositoryPolicy, include bool) Step {
+	with := map[string]string{
+		"language": "${{ matrix.language }}",
+	}
+	if include {
+		with["build_step_name"] = "${{ matrix.build_step_name }}"
+		with["path"] = "${{ matrix.path }}"
+	}
+	if policy.UploadDB {
+		with["upload_db"] = "true"
+	}
+	if policy.Config != "" {
+		with["config"] = policy.Config
 	}
 
-	return string(workflowBytes), nil
+	return Step{
+		Name: "Run Code Scanning",
+		Uses: fmt.Sprintf("%s@main", ReusableActionName),
+		With: with,
+	}
 }
 
-func GenerateEMASSJSON() (string, error) {
+func RequiresMatrixInclude(entries []MatrixInclude, defaultRunner RunnerLabels) bool {
+	languages := map[string]bool{}
+	for _, entry := range entries {
+		if languages[entry.Language] || entry.RunsOn.String() != defaultRunner.String() || entry.BuildStepName != "" || entry.Path != DefaultMatrixPath {
+			return true
+		}
+		languages[entry.Language] = true
+	}
+
+	return false
+}
+
+func GenerateEMASSJSON(entry *InventoryEntry) (string, error) {
 	emassJSON := &EMASS{
 		SystemID:         0,
 		SystemName:       "<system_name>",
 		SystemOwnerName:  "<full_name>",
 		SystemOwnerEmail: "<email>",
 	}
+	if entry != nil {
+		emassJSON = &EMASS{
+			SystemID:         entry.SystemID,
+			SystemName:       entry.SystemName,
+			SystemOwnerName:  entry.SystemOwnerName,
+			SystemOwnerEmail: entry.SystemOwnerEmail,
+		}
+	}
 
 	emassBytes, err := json.Marshal(emassJSON)
 	if err != nil {
@@ -93,14 +180,6 @@ func GenerateEMASSJSON() (string, error) {
 	return string(emassBytes), nil
 }
 
-func GenerateRandomWeeklyCron() string {
-	minute := rand.Intn(60)
-	hour := rand.Intn(24)
-	dayOfWeek := rand.Intn(7)
-
-	return fmt.Sprintf("%d %d * * %d", minute, hour, dayOfWeek)
-}
-
 func Contains(s []string, v string) bool {
 	for _, e := range s {
 		if e == v {
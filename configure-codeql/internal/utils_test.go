@@ -0,0 +1,66 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v52/github"
+	"gopkg.in/yaml.v3"
+)
+
+func TestLatestPullRequest(t *testing.T) {
+	pullRequest := func(number int, updated time.Time) *github.PullRequest {
+		return &github.PullRequest{
+			Number:    github.Int(number),
+			UpdatedAt: &github.Timestamp{Time: updated},
+		}
+	}
+	now := time.Now()
+
+	tests := []struct {
+		name         string
+		pullRequests []*github.PullRequest
+		number       int
+	}{
+		{name: "no pull requests"},
+		{name: "single pull request", pullRequests: []*github.PullRequest{pullRequest(3, now)}, number: 3},
+		{
+			name:         "old pull request updated most recently",
+			pullRequests: []*github.PullRequest{pullRequest(2, now), pullRequest(7, now.Add(-time.Hour)), pullRequest(5, now.Add(-2*time.Hour))},
+			number:       7,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			latest := LatestPullRequest(test.pullRequests)
+			if latest.GetNumber() != test.number {
+				t.Errorf("got pull request #%d, want #%d", latest.GetNumber(), test.number)
+			}
+		})
+	}
+}
+
+func TestGenerateCodeQLWorkflowLanguageInput(t *testing.T) {
+	content, err := GenerateCodeQLWorkflow([]string{"go"}, "main", "0 2 * * 1", (&RolloutPolicy{}).Resolve(RepositoryAttributes{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var workflow struct {
+		Jobs map[string]struct {
+			Steps []struct {
+				With map[string]string `yaml:"with"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	err = yaml.Unmarshal([]byte(content), &workflow)
+	if err != nil {
+		t.Fatalf("failed to unmarshal workflow: %v", err)
+	}
+	want := map[string]string{"language": "${{ matrix.language }}"}
+	if with := workflow.Jobs["analyze"].Steps[0].With; !reflect.DeepEqual(with, want) {
+		t.Errorf("got with %v, want %v", with, want)
+	}
+}
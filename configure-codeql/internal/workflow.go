@@ -0,0 +1,444 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	CodeQLActionPrefix   = "github/codeql-action/"
+	DefaultWorkflowPath  = ".github/workflows/codeql-analysis.yml"
+	FallbackWorkflowPath = ".github/workflows/ois-codeql-analysis.yml"
+	ReusableActionName   = "department-of-veterans-affairs/codeql-tools/codeql-analysis"
+	WorkflowsDirectory   = ".github/workflows"
+
+	JobDirect    = "direct"
+	JobReusable  = "reusable"
+	JobUnrelated = "unrelated"
+)
+
+var CodeQLAnalysisActions = []string{
+	"init",
+	"autobuild",
+	"analyze",
+}
+
+type WorkflowFile struct {
+	Path    string
+	Content string
+	Jobs    map[string]string
+	Crons   []string
+	Error   error
+}
+
+type WorkflowAnalysis struct {
+	Workflows []*WorkflowFile
+}
+
+func (m *Manager) AnalyzeWorkflows(owner, repo, ref string) (*WorkflowAnalysis, error) {
+	paths, err := m.ListWorkflowFiles(owner, repo, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := &WorkflowAnalysis{}
+	for _, workflowPath := range paths {
+		content, _, err := m.GetFileContents(owner, repo, workflowPath, ref)
+		if err != nil {
+			return nil, fmt.Errorf("failed to retrieve workflow %s: %w", workflowPath, err)
+		}
+		analysis.Workflows = append(analysis.Workflows, ClassifyWorkflow(workflowPath, content))
+	}
+
+	return analysis, nil
+}
+
+func ClassifyWorkflow(path, content string) *WorkflowFile {
+	workflowFile := &WorkflowFile{
+		Path:    path,
+		Content: content,
+		Jobs:    map[string]string{},
+	}
+
+	var definition workflowDefinition
+	err := yaml.Unmarshal([]byte(content), &definition)
+	if err != nil {
+		workflowFile.Error = fmt.Errorf("failed to unmarshal workflow: %w", err)
+		return workflowFile
+	}
+
+	if schedule := mappingValue(&definition.On, "schedule"); schedule != nil && schedule.Kind == yaml.SequenceNode {
+		for _, entry := range schedule.Content {
+			if cron := mappingValue(entry, "cron"); cron != nil {
+				workflowFile.Crons = append(workflowFile.Crons, cron.Value)
+			}
+		}
+	}
+
+	for name, job := range definition.Jobs {
+		classification := JobUnrelated
+		if strings.Contains(strings.ToLower(job.Uses), SourceRepo) {
+			classification = JobReusable
+		}
+		for _, step := range job.Steps {
+			uses := strings.ToLower(step.Uses)
+			if strings.HasPrefix(uses, ReusableActionName) {
+				classification = JobReusable
+				break
+			}
+			if Contains(CodeQLAnalysisActions, CodeQLAction(uses)) {
+				classification = JobDirect
+			}
+		}
+		workflowFile.Jobs[name] = classification
+	}
+
+	return workflowFile
+}
+
+func CodeQLAction(uses string) string {
+	if !strings.HasPrefix(uses, CodeQLActionPrefix) {
+		return ""
+	}
+
+	return strings.Split(strings.TrimPrefix(uses, CodeQLActionPrefix), "@")[0]
+}
+
+func (w *WorkflowFile) Has(classification string) bool {
+	for _, jobClassification := range w.Jobs {
+		if jobClassification == classification {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (w *WorkflowFile) Summary() string {
+	if w.Error != nil {
+		return w.Error.Error()
+	}
+
+	var names []string
+	for name := range w.Jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var jobs []string
+	for _, name := range names {
+		jobs = append(jobs, fmt.Sprintf("%s=%s", name, w.Jobs[name]))
+	}
+
+	return strings.Join(jobs, ", ")
+}
+
+func (a *WorkflowAnalysis) DirectWorkflows() []*WorkflowFile {
+	return a.filter(JobDirect)
+}
+
+func (a *WorkflowAnalysis) ReusableWorkflows() []*WorkflowFile {
+	return a.filter(JobReusable)
+}
+
+func (a *WorkflowAnalysis) Workflow(path string) *WorkflowFile {
+	for _, workflowFile := range a.Workflows {
+		if workflowFile.Path == path {
+			return workflowFile
+		}
+	}
+
+	return nil
+}
+
+func (a *WorkflowAnalysis) filter(classification string) []*WorkflowFile {
+	var workflows []*WorkflowFile
+	for _, workflowFile := range a.Workflows {
+		if workflowFile.Has(classification) {
+			workflows = append(workflows, workflowFile)
+		}
+	}
+
+	return workflows
+}
+
+func IsWorkflowFile(name string) bool {
+	extension := strings.ToLower(path.Ext(name))
+	return extension == ".yml" || extension == ".yaml"
+}
+
+func MergeCodeQLWorkflow(existing string, languages []string, defaultBranch string, policy *RepositoryPolicy) (string, []string, bool, error) {
+	var document yaml.Node
+	err := yaml.Unmarshal([]byte(existing), &document)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to unmarshal workflow: %w", err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return "", nil, false, fmt.Errorf("workflow is not a YAML mapping")
+	}
+	root := document.Content[0]
+
+	jobs := mappingValue(root, "jobs")
+	if jobs == nil || jobs.Kind != yaml.MappingNode {
+		return existing, nil, false, nil
+	}
+
+	var changes []string
+	converted := false
+	for i := 0; i+1 < len(jobs.Content); i += 2 {
+		jobName := jobs.Content[i].Value
+		job := jobs.Content[i+1]
+		steps := mappingValue(job, "steps")
+		if steps == nil || steps.Kind != yaml.SequenceNode {
+			continue
+		}
+
+		initStep := codeQLStep(steps, "init")
+		if initStep == nil {
+			continue
+		}
+		initUses := mappingValue(initStep, "uses").Value
+		inputs, unsupported := reusableStepInputs(initStep, policy)
+		if len(unsupported) > 0 {
+			changes = append(changes, fmt.Sprintf("Left job `%s` unchanged, the reusable `codeql-analysis` action cannot take `%s` from `%s`", jobName, strings.Join(unsupported, "`, `"), initUses))
+			continue
+		}
+
+		var reusableStep *yaml.Node
+		var mergedSteps []*yaml.Node
+		for _, step := range steps.Content {
+			uses := mappingValue(step, "uses")
+			if uses == nil {
+				mergedSteps = append(mergedSteps, step)
+				continue
+			}
+
+			switch CodeQLAction(strings.ToLower(uses.Value)) {
+			case "init":
+				if reusableStep == nil {
+					reusableStep = &yaml.Node{}
+					mergedSteps = append(mergedSteps, reusableStep)
+					changes = append(changes, fmt.Sprintf("Replaced `%s` in job `%s` with the reusable `codeql-analysis` action", uses.Value, jobName))
+					continue
+				}
+				changes = append(changes, fmt.Sprintf("Removed `%s` from job `%s`, the reusable `codeql-analysis` action performs this step", uses.Value, jobName))
+			case "autobuild", "analyze":
+				changes = append(changes, fmt.Sprintf("Removed `%s` from job `%s`, the reusable `codeql-analysis` action performs this step", uses.Value, jobName))
+			default:
+				mergedSteps = append(mergedSteps, step)
+			}
+		}
+		steps.Content = mergedSteps
+		converted = true
+
+		var keys []string
+		for key := range inputs {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			changes = append(changes, fmt.Sprintf("Moved `%s` from `%s` in job `%s` to the reusable `codeql-analysis` action", key, initUses, jobName))
+		}
+
+		matrixChanges, include, err := reconcileLanguageMatrix(job, jobName, languages, policy)
+		if err != nil {
+			return "", nil, false, err
+		}
+		changes = append(changes, matrixChanges...)
+
+		step := NewReusableStep(policy, include)
+		for key, value := range inputs {
+			step.With[key] = value
+		}
+		err = reusableStep.Encode(step)
+		if err != nil {
+			return "", nil, false, fmt.Errorf("failed to encode reusable step: %w", err)
+		}
+	}
+
+	if !converted {
+		return existing, changes, false, nil
+	}
+
+	var buffer bytes.Buffer
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	err = encoder.Encode(&document)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to marshal workflow: %w", err)
+	}
+	err = encoder.Close()
+	if err != nil {
+		return "", nil, false, fmt.Errorf("failed to marshal workflow: %w", err)
+	}
+
+	return buffer.String(), changes, true, nil
+}
+
+func codeQLStep(steps *yaml.Node, action string) *yaml.Node {
+	for _, step := range steps.Content {
+		uses := mappingValue(step, "uses")
+		if uses != nil && CodeQLAction(strings.ToLower(uses.Value)) == action {
+			return step
+		}
+	}
+
+	return nil
+}
+
+func reusableStepInputs(initStep *yaml.Node, policy *RepositoryPolicy) (map[string]string, []string) {
+	inputs := map[string]string{}
+	var unsupported []string
+	with := mappingValue(initStep, "with")
+	if with == nil || with.Kind != yaml.MappingNode {
+		return inputs, nil
+	}
+
+	for i := 0; i+1 < len(with.Content); i += 2 {
+		key := with.Content[i].Value
+		value := with.Content[i+1]
+		switch {
+		case key == "languages":
+		case key == "debug" && value.Kind == yaml.ScalarNode:
+			inputs[key] = value.Value
+		case key == "config" && value.Kind == yaml.ScalarNode && policy.Config == "":
+			inputs[key] = value.Value
+		default:
+			unsupported = append(unsupported, key)
+		}
+	}
+
+	return inputs, unsupported
+}
+
+func reconcileLanguageMatrix(job *yaml.Node, jobName string, languages []string, policy *RepositoryPolicy) ([]string, bool, error) {
+	strategy := mappingValue(job, "strategy")
+	if strategy == nil || strategy.Kind != yaml.MappingNode {
+		strategy = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setMappingValue(job, "strategy", strategy)
+	}
+	matrix := mappingValue(strategy, "matrix")
+	if matrix == nil || matrix.Kind != yaml.MappingNode {
+		matrix = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		setMappingValue(strategy, "matrix", matrix)
+	}
+
+	var changes []string
+	matrixLanguages := mappingValue(matrix, "language")
+	include := mappingValue(matrix, "include")
+	if matrixLanguages == nil && include != nil && include.Kind == yaml.SequenceNode {
+		includeChanges, err := reconcileMatrixInclude(include, jobName, languages, policy)
+		return includeChanges, true, err
+	}
+	if matrixLanguages == nil && include == nil {
+		entries := policy.MatrixEntries(languages)
+		defaultRunner := policy.Runner(DefaultRunnerKey)
+		if RequiresMatrixInclude(entries, defaultRunner) {
+			include = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+			setMappingValue(matrix, "include", include)
+			for _, entry := range entries {
+				if entry.RunsOn.String() != defaultRunner.String() {
+					setMappingValue(job, "runs-on", scalarNode("${{ matrix.runs-on }}"))
+					changes = append(changes, fmt.Sprintf("Set `runs-on` of job `%s` to the runner of each language", jobName))
+					break
+				}
+			}
+			includeChanges, err := reconcileMatrixInclude(include, jobName, languages, policy)
+			return append(changes, includeChanges...), true, err
+		}
+	}
+
+	if matrixLanguages == nil || matrixLanguages.Kind != yaml.SequenceNode {
+		sequence := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		if matrixLanguages != nil {
+			if matrixLanguages.Kind == yaml.ScalarNode && !strings.Contains(matrixLanguages.Value, "${{") {
+				sequence.Content = append(sequence.Content, matrixLanguages)
+			} else {
+				changes = append(changes, fmt.Sprintf("Replaced the language matrix of job `%s`", jobName))
+			}
+		}
+		matrixLanguages = sequence
+		setMappingValue(matrix, "language", matrixLanguages)
+	}
+
+	var covered []string
+	for _, node := range matrixLanguages.Content {
+		covered = append(covered, utils.NormalizeCodeQLLanguage(node.Value)...)
+	}
+	for _, language := range languages {
+		if Contains(covered, language) {
+			continue
+		}
+		matrixLanguages.Content = append(matrixLanguages.Content, scalarNode(language))
+		changes = append(changes, fmt.Sprintf("Added `%s` to the language matrix of job `%s`", language, jobName))
+	}
+
+	return changes, false, nil
+}
+
+func reconcileMatrixInclude(include *yaml.Node, jobName string, languages []string, policy *RepositoryPolicy) ([]string, error) {
+	var changes []string
+	var covered []string
+	for _, entry := range include.Content {
+		language := mappingValue(entry, "language")
+		if language == nil {
+			continue
+		}
+		covered = append(covered, utils.NormalizeCodeQLLanguage(language.Value)...)
+		if mappingValue(entry, "path") == nil {
+			setMappingValue(entry, "path", scalarNode(DefaultMatrixPath))
+			changes = append(changes, fmt.Sprintf("Set `path` of the `%s` entry in the language matrix of job `%s` to `%s`", language.Value, jobName, DefaultMatrixPath))
+		}
+	}
+
+	var missing []string
+	for _, language := range languages {
+		if !Contains(covered, language) {
+			missing = append(missing, language)
+		}
+	}
+	for _, entry := range policy.MatrixEntries(missing) {
+		var node yaml.Node
+		err := node.Encode(entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode matrix entry: %w", err)
+		}
+		include.Content = append(include.Content, &node)
+		changes = append(changes, fmt.Sprintf("Added `%s` to the language matrix of job `%s`", entry.Language, jobName))
+	}
+
+	return changes, nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content, scalarNode(key), value)
+}
@@ -0,0 +1,366 @@
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestClassifyWorkflow(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		jobs    map[string]string
+	}{
+		{
+			name: "direct codeql action",
+			content: `
+jobs:
+  analyze:
+    steps:
+      - uses: actions/checkout@v3
+      - uses: github/codeql-action/init@v2
+      - uses: github/codeql-action/analyze@v2
+`,
+			jobs: map[string]string{"analyze": JobDirect},
+		},
+		{
+			name: "autobuild only",
+			content: `
+jobs:
+  build:
+    steps:
+      - uses: github/codeql-action/autobuild@v2
+`,
+			jobs: map[string]string{"build": JobDirect},
+		},
+		{
+			name: "third-party sarif upload",
+			content: `
+jobs:
+  trivy:
+    steps:
+      - uses: aquasecurity/trivy-action@master
+      - uses: github/codeql-action/upload-sarif@v2
+`,
+			jobs: map[string]string{"trivy": JobUnrelated},
+		},
+		{
+			name: "reusable action",
+			content: `
+jobs:
+  analyze:
+    steps:
+      - uses: department-of-veterans-affairs/codeql-tools/codeql-analysis@main
+`,
+			jobs: map[string]string{"analyze": JobReusable},
+		},
+		{
+			name: "mixed jobs",
+			content: `
+jobs:
+  build:
+    steps:
+      - run: make
+  analyze:
+    steps:
+      - uses: GitHub/CodeQL-Action/init@v2
+`,
+			jobs: map[string]string{"build": JobUnrelated, "analyze": JobDirect},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			workflowFile := ClassifyWorkflow("workflow.yml", test.content)
+			if workflowFile.Error != nil {
+				t.Fatalf("unexpected error: %v", workflowFile.Error)
+			}
+			if !reflect.DeepEqual(workflowFile.Jobs, test.jobs) {
+				t.Errorf("got jobs %v, want %v", workflowFile.Jobs, test.jobs)
+			}
+		})
+	}
+}
+
+func TestClassifyWorkflowCrons(t *testing.T) {
+	workflowFile := ClassifyWorkflow("workflow.yml", `
+on:
+  schedule:
+    - cron: '0 2 * * 1'
+    - cron: '30 4 * * 3'
+jobs: {}
+`)
+	want := []string{"0 2 * * 1", "30 4 * * 3"}
+	if !reflect.DeepEqual(workflowFile.Crons, want) {
+		t.Errorf("got crons %v, want %v", workflowFile.Crons, want)
+	}
+}
+
+func TestClassifyWorkflowInvalid(t *testing.T) {
+	workflowFile := ClassifyWorkflow("workflow.yml", "jobs: [")
+	if workflowFile.Error == nil {
+		t.Fatal("expected an error for invalid YAML")
+	}
+}
+
+func TestMergeCodeQLWorkflow(t *testing.T) {
+	defaultPolicy := (&RolloutPolicy{}).Resolve(RepositoryAttributes{})
+
+	tests := []struct {
+		name      string
+		existing  string
+		languages []string
+		changed   bool
+		matrix    map[string]interface{}
+		runsOn    interface{}
+		with      map[string]interface{}
+		note      string
+	}{
+		{
+			name: "adds missing languages and keeps aliases",
+			existing: `
+on: push
+jobs:
+  analyze:
+    runs-on: [self-hosted, linux]
+    strategy:
+      matrix:
+        language: [javascript-typescript, actions]
+    steps:
+      - uses: actions/checkout@v3
+      - uses: github/codeql-action/init@v2
+        with:
+          languages: ${{ matrix.language }}
+      - uses: github/codeql-action/analyze@v2
+`,
+			languages: []string{"javascript", "typescript", "go"},
+			changed:   true,
+			matrix: map[string]interface{}{
+				"language": []interface{}{"javascript-typescript", "actions", "go"},
+			},
+			runsOn: []interface{}{"self-hosted", "linux"},
+			with:   map[string]interface{}{"language": "${{ matrix.language }}"},
+		},
+		{
+			name: "updates include entries",
+			existing: `
+jobs:
+  analyze:
+    runs-on: ${{ matrix.runs-on }}
+    strategy:
+      matrix:
+        include:
+          - language: java-kotlin
+            runs-on: windows-latest
+            build_step_name: gradle
+    steps:
+      - uses: github/codeql-action/init@v2
+      - uses: github/codeql-action/autobuild@v2
+      - uses: github/codeql-action/analyze@v2
+`,
+			languages: []string{"java", "kotlin", "python"},
+			changed:   true,
+			matrix: map[string]interface{}{
+				"include": []interface{}{
+					map[string]interface{}{"language": "java-kotlin", "runs-on": "windows-latest", "build_step_name": "gradle", "path": "."},
+					map[string]interface{}{"language": "python", "runs-on": "ubuntu-latest", "build_step_name": "", "path": "."},
+				},
+			},
+			runsOn: "${{ matrix.runs-on }}",
+			with: map[string]interface{}{
+				"language":        "${{ matrix.language }}",
+				"build_step_name": "${{ matrix.build_step_name }}",
+				"path":            "${{ matrix.path }}",
+			},
+		},
+		{
+			name: "generates include entries for per-language runners",
+			existing: `
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: github/codeql-action/init@v2
+      - uses: github/codeql-action/analyze@v2
+`,
+			languages: []string{"go", "swift"},
+			changed:   true,
+			matrix: map[string]interface{}{
+				"include": []interface{}{
+					map[string]interface{}{"language": "go", "runs-on": "ubuntu-latest", "build_step_name": "", "path": "."},
+					map[string]interface{}{"language": "swift", "runs-on": "macos-latest", "build_step_name": "", "path": "."},
+				},
+			},
+			runsOn: "${{ matrix.runs-on }}",
+			with: map[string]interface{}{
+				"language":        "${{ matrix.language }}",
+				"build_step_name": "${{ matrix.build_step_name }}",
+				"path":            "${{ matrix.path }}",
+			},
+		},
+		{
+			name: "moves init config to the reusable step",
+			existing: `
+jobs:
+  analyze:
+    runs-on: ubuntu-latest
+    strategy:
+      matrix:
+        language: [go]
+    steps:
+      - uses: github/codeql-action/init@v2
+        with:
+          languages: ${{ matrix.language }}
+          config: |
+            paths-ignore:
+              - vendor
+          debug: true
+      - uses: github/codeql-action/analyze@v2
+`,
+			languages: []string{"go"},
+			changed:   true,
+			matrix: map[string]interface{}{
+				"language": []interface{}{"go"},
+			},
+			runsOn: "ubuntu-latest",
+			with: map[string]interface{}{
+				"language": "${{ matrix.language }}",
+				"config":   "paths-ignore:\n  - vendor\n",
+				"debug":    "true",
+			},
+		},
+		{
+			name: "leaves sarif upload workflows unchanged",
+			existing: `
+jobs:
+  trivy:
+    steps:
+      - uses: aquasecurity/trivy-action@master
+      - uses: github/codeql-action/upload-sarif@v2
+`,
+			languages: []string{"go"},
+			changed:   false,
+		},
+		{
+			name: "leaves jobs without an init step unchanged",
+			existing: `
+jobs:
+  build:
+    steps:
+      - uses: github/codeql-action/autobuild@v2
+      - uses: github/codeql-action/analyze@v2
+`,
+			languages: []string{"go"},
+			changed:   false,
+		},
+		{
+			name: "leaves jobs with custom queries unchanged",
+			existing: `
+jobs:
+  analyze:
+    steps:
+      - uses: github/codeql-action/init@v2
+        with:
+          languages: go
+          config-file: .github/codeql/codeql-config.yml
+          queries: security-extended
+      - uses: github/codeql-action/analyze@v2
+`,
+			languages: []string{"go"},
+			changed:   false,
+			note:      "Left job `analyze` unchanged, the reusable `codeql-analysis` action cannot take `config-file`, `queries` from `github/codeql-action/init@v2`",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, changes, converted, err := MergeCodeQLWorkflow(test.existing, test.languages, "main", defaultPolicy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !test.changed {
+				if converted || content != test.existing {
+					t.Fatalf("expected workflow to be unchanged, got changes %v", changes)
+				}
+				var notes []string
+				if test.note != "" {
+					notes = []string{test.note}
+				}
+				if !reflect.DeepEqual(changes, notes) {
+					t.Errorf("got changes %v, want %v", changes, notes)
+				}
+				return
+			}
+			if !converted || len(changes) == 0 {
+				t.Fatal("expected changes")
+			}
+
+			var workflow struct {
+				Jobs map[string]struct {
+					RunsOn   interface{} `yaml:"runs-on"`
+					Strategy struct {
+						Matrix map[string]interface{} `yaml:"matrix"`
+					} `yaml:"strategy"`
+					Steps []map[string]interface{} `yaml:"steps"`
+				} `yaml:"jobs"`
+			}
+			err = yaml.Unmarshal([]byte(content), &workflow)
+			if err != nil {
+				t.Fatalf("failed to unmarshal merged workflow: %v", err)
+			}
+			job := workflow.Jobs["analyze"]
+			if !reflect.DeepEqual(job.Strategy.Matrix, test.matrix) {
+				t.Errorf("got matrix %v, want %v", job.Strategy.Matrix, test.matrix)
+			}
+			if !reflect.DeepEqual(job.RunsOn, test.runsOn) {
+				t.Errorf("got runs-on %v, want %v", job.RunsOn, test.runsOn)
+			}
+
+			var reusable map[string]interface{}
+			for _, step := range job.Steps {
+				uses, _ := step["uses"].(string)
+				if CodeQLAction(uses) != "" {
+					t.Errorf("found unconverted step %s", uses)
+				}
+				if uses == ReusableActionName+"@main" {
+					reusable = step
+				}
+			}
+			if reusable == nil {
+				t.Fatal("reusable codeql-analysis step not found")
+			}
+			if !reflect.DeepEqual(reusable["with"], test.with) {
+				t.Errorf("got with %v, want %v", reusable["with"], test.with)
+			}
+		})
+	}
+}
+
+func TestMergeCodeQLWorkflowPolicyConfig(t *testing.T) {
+	policy := (&RolloutPolicy{Defaults: PolicySettings{Config: ".github/codeql/regulated.yml"}}).Resolve(RepositoryAttributes{})
+	existing := `
+jobs:
+  analyze:
+    steps:
+      - uses: github/codeql-action/init@v2
+        with:
+          config: |
+            paths-ignore:
+              - vendor
+      - uses: github/codeql-action/analyze@v2
+`
+
+	content, changes, converted, err := MergeCodeQLWorkflow(existing, []string{"go"}, "main", policy)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if converted || content != existing {
+		t.Fatal("expected workflow to be unchanged when the policy sets its own config")
+	}
+	want := []string{"Left job `analyze` unchanged, the reusable `codeql-analysis` action cannot take `config` from `github/codeql-action/init@v2`"}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("got changes %v, want %v", changes, want)
+	}
+}
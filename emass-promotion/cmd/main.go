@@ -5,11 +5,12 @@ import (
 	"fmt"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/department-of-veterans-affairs/codeql-tools/emass-promotion/internal"
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
 	"github.com/department-of-veterans-affairs/codeql-tools/utils"
-	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -21,48 +22,117 @@ func init() {
 	}
 }
 
-type CustomFormatter struct{}
+func main() {
+	config := internal.ParseInput()
+
+	log.SetFormatter(&logging.Formatter{})
+	globalLogger := log.New()
+	globalLogger.SetFormatter(&logging.Formatter{})
+	summary := utils.NewSummary()
+	globalLogger.AddHook(summary)
+
+	stream, err := logging.NewStream("emass-promotion", config.EventsPath)
+	if err != nil {
+		globalLogger.Fatalf("%v", err)
+	}
+	defer stream.Close()
+	globalLogger.AddHook(stream.Hook(config.Org))
 
-func (f *CustomFormatter) Format(entry *logrus.Entry) ([]byte, error) {
-	if repoValue, ok := entry.Data["repo"]; ok {
-		repo := fmt.Sprint(repoValue)
-		if eventValue, ok := entry.Data["event"]; ok {
-			event := fmt.Sprint(eventValue)
-			return []byte(fmt.Sprintf("[%s]: [%s] %s\n", repo, event, entry.Message)), nil
+	if !config.Enterprise {
+		err = run(config, globalLogger)
+		if err != nil {
+			globalLogger.Fatalf("%v", err)
 		}
-		return []byte(fmt.Sprintf("[%s]: %s\n", repo, entry.Message)), nil
+		summary.Log(globalLogger)
+		utils.PublishReport(globalLogger, summary.Report("eMASS Promotion"), config.ReportPath)
+		return
 	}
 
-	return []byte(fmt.Sprintf("%s\n", entry.Message)), nil
-}
+	globalLogger.Infof("Creating eMASS Promotion GitHub App client")
+	emassAppClient, err := utils.NewGitHubAppClient(config.EMASSPromotionAppID, config.EMASSPromotionPrivateKey)
+	if err != nil {
+		globalLogger.Fatalf("failed to create eMASS Promotion GitHub App client: %v", err)
+	}
+	globalLogger.Debugf("eMASS Promotion GitHub App client created")
 
-func main() {
-	config := internal.ParseInput()
+	globalLogger.Infof("Retrieving eMASS Promotion app installations")
+	installations, err := utils.ListOrganizationInstallations(context.Background(), emassAppClient)
+	if err != nil {
+		globalLogger.Fatalf("failed to list eMASS Promotion app installations: %v", err)
+	}
+	globalLogger.Debugf("Retrieved %d eMASS Promotion app installations", len(installations))
 
-	globalLogger := log.New()
-	globalLogger.SetFormatter(&CustomFormatter{})
+	emassOrgInstallationID := config.EMASSOrgInstallationID
+	if emassOrgInstallationID == 0 {
+		id, ok := utils.InstallationIDs(installations)[config.EMASSOrg]
+		if !ok {
+			globalLogger.Fatalf("eMASS Promotion app is not installed in the eMASS organization %s", config.EMASSOrg)
+		}
+		emassOrgInstallationID = id
+	}
 
+	organizations := map[string]*utils.Summary{}
+	var failed []string
+	for _, installation := range installations {
+		if installation.Org == config.EMASSOrg {
+			continue
+		}
+
+		inputs := map[string]string{
+			"emass_organization_installation_id": strconv.FormatInt(emassOrgInstallationID, 10),
+			"emass_promotion_installation_id":    strconv.FormatInt(installation.InstallationID, 10),
+		}
+		orgConfig := internal.ParseInputWithOverrides(utils.OrganizationInputs(config.OrgOverrides, installation.Org, inputs))
+
+		orgLogger, orgSummary := utils.NewOrganizationLogger(globalLogger, summary, installation.Org, &logging.Formatter{Org: installation.Org})
+		orgLogger.AddHook(stream.Hook(installation.Org))
+		organizations[installation.Org] = orgSummary
+
+		globalLogger.Infof("Processing organization %s", installation.Org)
+		err = run(orgConfig, orgLogger)
+		if err != nil {
+			globalLogger.Errorf("failed to process organization %s: %v", installation.Org, err)
+			failed = append(failed, installation.Org)
+			continue
+		}
+		orgSummary.Log(orgLogger)
+	}
+	for org := range config.OrgOverrides {
+		if _, ok := organizations[org]; !ok {
+			globalLogger.Warnf("Overrides configured for organization %s, but the eMASS Promotion app is not installed", org)
+		}
+	}
+
+	summary.Log(globalLogger)
+	utils.LogOrganizations(globalLogger, organizations, failed)
+
+	report := summary.Report("eMASS Promotion")
+	report.AddOrganizations(organizations, failed)
+	utils.PublishReport(globalLogger, report, config.ReportPath)
+}
+
+func run(config *internal.Input, globalLogger *log.Logger) error {
 	globalLogger.Infof("Creating admin GitHub client")
 	adminClient := utils.NewGitHubClient(config.AdminToken)
 
 	globalLogger.Infof("Creating eMASS Promotion GitHub App client")
 	emassAppClient, err := utils.NewGitHubAppClient(config.EMASSPromotionAppID, config.EMASSPromotionPrivateKey)
 	if err != nil {
-		globalLogger.Fatalf("failed to create eMASS Promotion GitHub App client: %v", err)
+		return fmt.Errorf("failed to create eMASS Promotion GitHub App client: %v", err)
 	}
 	globalLogger.Debugf("eMASS Promotion GitHub App client created")
 
 	globalLogger.Infof("Creating eMASS Org GitHub App Installation client")
 	emassOrgClient, err := utils.NewGitHubInstallationClient(config.EMASSPromotionAppID, config.EMASSOrgInstallationID, config.EMASSPromotionPrivateKey)
 	if err != nil {
-		globalLogger.Fatalf("failed to create eMASS Promotion GitHub App client: %v", err)
+		return fmt.Errorf("failed to create eMASS Promotion GitHub App client: %v", err)
 	}
 	globalLogger.Debugf("eMASS Promotion GitHub App client created")
 
 	globalLogger.Infof("Creating eMASS Org GitHub App Installation client")
 	emassClient, err := utils.NewGitHubInstallationClient(config.EMASSPromotionAppID, config.EMASSPromotionInstallationID, config.EMASSPromotionPrivateKey)
 	if err != nil {
-		globalLogger.Fatalf("failed to create eMASS Promotion GitHub App client: %v", err)
+		return fmt.Errorf("failed to create eMASS Promotion GitHub App client: %v", err)
 	}
 	globalLogger.Debugf("eMASS Promotion GitHub App client created")
 
@@ -81,32 +151,41 @@ func main() {
 	globalLogger.Infof("Retrieving repositories")
 	repos, err := m.ListRepos()
 	if err != nil {
-		globalLogger.Fatalf("failed to list repositories: %v", err)
+		return fmt.Errorf("failed to list repositories: %v", err)
 	}
 	globalLogger.Debugf("Retrieved %d repositories", len(repos))
 
 	globalLogger.Infof("Retrieving eMASS system list")
 	emassSystemIDs, err := m.GetEMASSSystemList(m.Config.EMASSSystemListOrg, m.Config.EMASSSystemListRepo, m.Config.EMASSSystemListPath)
 	if err != nil {
-		globalLogger.Fatalf("failed to get eMASS system list: %v", err)
+		return fmt.Errorf("failed to get eMASS system list: %v", err)
 	}
 	m.EMASSSystemIDs = emassSystemIDs
 	globalLogger.Debugf("Retrieved %d eMASS system IDs", len(emassSystemIDs))
 
-	if config.Repo == "" {
-		for _, repo := range repos {
-			m.ProcessRepository(repo)
+	if config.ExemptionProperty != "" {
+		globalLogger.Infof("Retrieving allowed values of custom property %s", config.ExemptionProperty)
+		exemptions, err := utils.NewExemptionChecker(m.Context, m.AdminGitHubClient, config.Org, config.ExemptionProperty)
+		if err != nil {
+			return fmt.Errorf("failed to retrieve exemption property: %v", err)
 		}
+		m.Exemptions = exemptions
+		globalLogger.Debugf("Retrieved %d approved exemptions", len(exemptions.AllowedValues))
+	}
+
+	if config.Repo == "" {
+		globalLogger.Infof("Processing all repos with concurrency %d", config.Concurrency)
+		utils.ProcessConcurrently(repos, config.Concurrency, m.ProcessRepository)
 	} else {
 		globalLogger.WithField("repo", config.Repo).Infof("Processing single repo")
 		repo, resp, err := m.AdminGitHubClient.Repositories.Get(m.Context, config.Org, config.Repo)
 		if err != nil {
-			if resp.StatusCode == http.StatusNotFound {
-				globalLogger.Fatalf("repo does not exist: %v", err)
+			if resp != nil && resp.StatusCode == http.StatusNotFound {
+				return fmt.Errorf("repo does not exist: %v", err)
 			}
-			globalLogger.Fatalf("failed to retrieve repository: %v", err)
+			return fmt.Errorf("failed to retrieve repository: %v", err)
 		}
 		m.ProcessRepository(repo)
 	}
-
+	return nil
 }
@@ -4,87 +4,129 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/sethvargo/go-githubactions"
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
 )
 
 func ParseInput() *Input {
-	adminToken := githubactions.GetInput("admin_token")
+	return ParseInputWithOverrides(nil)
+}
+
+func ParseInputWithOverrides(overrides map[string]string) *Input {
+	action := utils.NewInputAction(overrides)
+
+	enterprise := strings.ToLower(strings.TrimSpace(action.GetInput("enterprise"))) == "true"
+
+	adminToken := action.GetInput("admin_token")
 	if adminToken == "" {
-		githubactions.Fatalf("admin_token input is required")
+		action.Fatalf("admin_token input is required")
 	}
 
-	daysToScan := githubactions.GetInput("days_to_scan")
+	concurrency := action.GetInput("concurrency")
+	if concurrency == "" {
+		concurrency = "1"
+	}
+
+	daysToScan := action.GetInput("days_to_scan")
 	if daysToScan == "" {
-		githubactions.Fatalf("days_to_scan input is required")
+		action.Fatalf("days_to_scan input is required")
 	}
 
-	emassOrg := githubactions.GetInput("emass_org")
+	emassOrg := action.GetInput("emass_org")
 	if emassOrg == "" {
-		githubactions.Fatalf("emass_org input is required")
+		action.Fatalf("emass_org input is required")
 	}
 
-	emassOrganizationInstallationID := githubactions.GetInput("emass_organization_installation_id")
-	if emassOrganizationInstallationID == "" {
-		githubactions.Fatalf("emass_organization_installation_id input is required")
+	emassOrganizationInstallationID := action.GetInput("emass_organization_installation_id")
+	if emassOrganizationInstallationID == "" && !enterprise {
+		action.Fatalf("emass_organization_installation_id input is required")
 	}
 
-	emassPromotionAppID := githubactions.GetInput("emass_promotion_app_id")
+	emassPromotionAppID := action.GetInput("emass_promotion_app_id")
 	if emassPromotionAppID == "" {
-		githubactions.Fatalf("emass_promotion_app_id input is required")
+		action.Fatalf("emass_promotion_app_id input is required")
 	}
 
-	emassPromotionPrivateKey := githubactions.GetInput("emass_promotion_private_key")
+	emassPromotionPrivateKey := action.GetInput("emass_promotion_private_key")
 	if emassPromotionPrivateKey == "" {
-		githubactions.Fatalf("emass_promotion_private_key input is required")
+		action.Fatalf("emass_promotion_private_key input is required")
 	}
 
-	emassPromotionInstallationID := githubactions.GetInput("emass_promotion_installation_id")
-	if emassPromotionInstallationID == "" {
-		githubactions.Fatalf("emass_promotion_installation_id input is required")
+	emassPromotionInstallationID := action.GetInput("emass_promotion_installation_id")
+	if emassPromotionInstallationID == "" && !enterprise {
+		action.Fatalf("emass_promotion_installation_id input is required")
 	}
 
-	emassSystemListOrg := githubactions.GetInput("emass_system_list_org")
+	emassSystemListOrg := action.GetInput("emass_system_list_org")
 	if emassSystemListOrg == "" {
-		githubactions.Fatalf("emass_system_list_path input is required")
+		action.Fatalf("emass_system_list_path input is required")
 	}
 
-	emassSystemListPath := githubactions.GetInput("emass_system_list_path")
+	emassSystemListPath := action.GetInput("emass_system_list_path")
 	if emassSystemListPath == "" {
-		githubactions.Fatalf("emass_system_list_path input is required")
+		action.Fatalf("emass_system_list_path input is required")
 	}
 
-	emassSystemListRepo := githubactions.GetInput("emass_system_list_repo")
+	emassSystemListRepo := action.GetInput("emass_system_list_repo")
 	if emassSystemListRepo == "" {
-		githubactions.Fatalf("emass_system_list_repo input is required")
+		action.Fatalf("emass_system_list_repo input is required")
 	}
 
-	org := githubactions.GetInput("org")
+	org := action.GetInput("org")
+
+	orgOverrides, err := utils.ParseOrgOverrides(action.GetInput("org_overrides"))
+	if err != nil {
+		action.Fatalf("invalid org_overrides input: %v", err)
+	}
 
-	repo := githubactions.GetInput("repo")
+	repo := action.GetInput("repo")
+
+	reportPath := action.GetInput("report_path")
+	if reportPath == "" {
+		reportPath = "report.json"
+	}
+
+	eventsPath := action.GetInput("events_path")
+
+	exemptionProperty := strings.TrimSpace(action.GetInput("exemption_property"))
+
+	concurrencyInt, err := strconv.Atoi(concurrency)
+	if err != nil || concurrencyInt < 1 {
+		action.Fatalf("concurrency input must be a positive integer")
+	}
 
 	daysToScanInt, err := strconv.Atoi(daysToScan)
 	if err != nil {
-		githubactions.Fatalf("days_to_scan input must be an integer")
+		action.Fatalf("days_to_scan input must be an integer")
 	}
 
-	emassOrganizationInstallationIDInt64, err := strconv.ParseInt(emassOrganizationInstallationID, 10, 64)
-	if err != nil {
-		githubactions.Fatalf("emass_organization_installation_id input must be an integer")
+	var emassOrganizationInstallationIDInt64 int64
+	if emassOrganizationInstallationID != "" {
+		emassOrganizationInstallationIDInt64, err = strconv.ParseInt(emassOrganizationInstallationID, 10, 64)
+		if err != nil {
+			action.Fatalf("emass_organization_installation_id input must be an integer")
+		}
 	}
 
 	emassPromotionAppIDInt64, err := strconv.ParseInt(emassPromotionAppID, 10, 64)
 	if err != nil {
-		githubactions.Fatalf("emass_promotion_app_id input must be an integer")
+		action.Fatalf("emass_promotion_app_id input must be an integer")
 	}
 
-	emassPromotionInstallationIDInt64, err := strconv.ParseInt(emassPromotionInstallationID, 10, 64)
-	if err != nil {
-		githubactions.Fatalf("emass_promotion_installation_id input must be an integer")
+	var emassPromotionInstallationIDInt64 int64
+	if emassPromotionInstallationID != "" {
+		emassPromotionInstallationIDInt64, err = strconv.ParseInt(emassPromotionInstallationID, 10, 64)
+		if err != nil {
+			action.Fatalf("emass_promotion_installation_id input must be an integer")
+		}
 	}
 
 	return &Input{
 		AdminToken:                   adminToken,
+		Concurrency:                  concurrencyInt,
 		DaysToScan:                   daysToScanInt,
+		Enterprise:                   enterprise,
+		EventsPath:                   eventsPath,
+		ExemptionProperty:            exemptionProperty,
 		EMASSOrg:                     strings.ToLower(emassOrg),
 		EMASSOrgInstallationID:       emassOrganizationInstallationIDInt64,
 		EMASSPromotionAppID:          emassPromotionAppIDInt64,
@@ -94,6 +136,8 @@ func ParseInput() *Input {
 		EMASSSystemListPath:          strings.ToLower(emassSystemListPath),
 		EMASSSystemListRepo:          strings.ToLower(emassSystemListRepo),
 		Org:                          strings.ToLower(org),
+		OrgOverrides:                 orgOverrides,
 		Repo:                         strings.ToLower(repo),
+		ReportPath:                   reportPath,
 	}
 }
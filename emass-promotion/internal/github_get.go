@@ -13,7 +13,7 @@ import (
 func (m *Manager) GetEMASSSystemList(owner, repo, path string) ([]int64, error) {
 	content, _, resp, err := m.AdminGitHubClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return nil, fmt.Errorf("file not found")
 		}
 
@@ -45,7 +45,7 @@ func (m *Manager) GetEMASSSystemList(owner, repo, path string) ([]int64, error)
 func (m *Manager) GetEMASSConfig(owner, repo, path string) (*EMASSConfig, error) {
 	content, _, resp, err := m.EMASSClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return nil, nil
 		}
 
@@ -12,7 +12,7 @@ import (
 func (m *Manager) FileExists(owner, repo, path string) (bool, error) {
 	_, _, resp, err := m.EMASSClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return false, nil
 		}
 
@@ -25,7 +25,7 @@ func (m *Manager) FileExists(owner, repo, path string) (bool, error) {
 func (m *Manager) repoExists(owner, repo string) (bool, error) {
 	_, resp, err := m.EMASSOrgClient.Repositories.Get(m.Context, owner, repo)
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return false, nil
 		}
 
@@ -118,7 +118,7 @@ func (m *Manager) RefExists(owner, repo, branch string) (bool, error) {
 	ref := fmt.Sprintf("heads/%s", branch)
 	_, resp, err := m.EMASSOrgClient.Git.GetRef(m.Context, owner, repo, ref)
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return false, nil
 		}
 
@@ -178,7 +178,7 @@ func (m *Manager) uploadSarif(owner, repo, sha, ref, sarif string) error {
 		Sarif:     &sarif,
 	})
 	if err != nil {
-		if resp.StatusCode == http.StatusAccepted {
+		if resp != nil && resp.StatusCode == http.StatusAccepted {
 			return nil
 		}
 		return fmt.Errorf("failed to upload sarif: %v", err)
@@ -6,6 +6,8 @@ import (
 	"os"
 	"strings"
 
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
 	"github.com/google/go-github/v52/github"
 	log "github.com/sirupsen/logrus"
 )
@@ -19,15 +21,14 @@ type Manager struct {
 	EMASSClient       *github.Client
 
 	Config       *Input
-	Logger       *log.Entry
 	GlobalLogger *log.Logger
 
 	EMASSSystemIDs []int64
+	Exemptions     *utils.ExemptionChecker
 }
 
 func (m *Manager) ProcessRepository(repo *github.Repository) {
 	logger := m.GlobalLogger.WithField("repo", repo.GetName())
-	m.Logger = logger
 
 	org := strings.ToLower(repo.GetOwner().GetLogin())
 	name := strings.ToLower(repo.GetName())
@@ -44,10 +45,23 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		logger.Fatalf("failed to check if repository is ignored: %v", err)
 	}
 	if repoIgnored {
-		logger.WithField("event", "skipped-ignored").Infof("Found .emass-repo-ignore file, skipping repository")
+		logger.WithField("event", logging.EventSkippedIgnored).Infof("Found .emass-repo-ignore file, skipping repository")
 		return
 	}
 
+	if m.Exemptions != nil {
+		logger.Infof("Checking if repository has an approved exemption")
+		exempt, err := m.Exemptions.Exempt(org, name, logger)
+		if err != nil {
+			logger.Errorf("failed to check if repository has an approved exemption, skipping repo: %v", err)
+			return
+		}
+		if exempt {
+			return
+		}
+		logger.Debugf("Repository does not have an approved exemption")
+	}
+
 	logger.Infof("Retrieving eMASS configuration file")
 	emassConfig, err := m.GetEMASSConfig(org, name, ".github/emass.json")
 	if err != nil {
@@ -55,11 +69,14 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		return
 	}
 	if emassConfig == nil {
-		logger.WithField("event", "emass-json-not-found").Warnf("Skipping repository as it does not contain an emass.json file")
+		logger.WithField("event", logging.EventEMASSJSONNotFound).Warnf("Skipping repository as it does not contain an emass.json file")
 		return
 	}
 	if !includesInt64(m.EMASSSystemIDs, emassConfig.SystemID) {
-		logger.WithField("event", "invalid-system-id").Warnf("Skipping repository as it contains an invalid System ID")
+		logger.WithFields(log.Fields{
+			"event":                logging.EventInvalidSystemID,
+			logging.DetailSystemID: emassConfig.SystemID,
+		}).Warnf("Skipping repository as it contains an invalid System ID")
 	}
 	logger.Debugf("eMASS configuration file processed")
 
@@ -86,7 +103,7 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		return
 	}
 	if len(databases) == 0 {
-		logger.WithField("event", "skipped-database-not-found").Infof("Skipping repository as it does not contain any new CodeQL databases")
+		logger.WithField("event", logging.EventSkippedDatabaseNotFound).Infof("Skipping repository as it does not contain any new CodeQL databases")
 		return
 	}
 
@@ -95,7 +112,7 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 			continue
 		}
 
-		path := fmt.Sprintf("%s-database.zip", database.Language)
+		path := fmt.Sprintf("%s-%s-database.zip", name, database.Language)
 		logger.Infof("Downloading CodeQL database")
 		err = m.downloadFileToDisk(database.URL, path)
 		if err != nil {
@@ -128,7 +145,7 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		return
 	}
 	if len(analyses) == 0 {
-		logger.WithField("event", "skipped-sarif-not-found").Infof("Skipping repository as it does not contain any new SARIF analyses")
+		logger.WithField("event", logging.EventSkippedSARIFNotFound).Infof("Skipping repository as it does not contain any new SARIF analyses")
 		return
 	}
 	logger.Debugf("CodeQL analyses retrieved")
@@ -189,7 +206,11 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 			logger.Errorf("failed to upload SARIF file to eMASS repository: %v", err)
 			continue
 		}
-		logger.WithField("event", "successful-upload").Infof("Successfully promoted %s artifacts to eMASS repository", analysis.Language)
+		logger.WithFields(log.Fields{
+			"event":                  logging.EventSuccessfulUpload,
+			logging.DetailAnalysisID: analysis.ID,
+			logging.DetailLanguage:   analysis.Language,
+		}).Infof("Successfully promoted %s artifacts to eMASS repository", analysis.Language)
 	}
-	logger.WithField("event", "finished-processing").Infof("Finished processed repository")
+	logger.WithField("event", logging.EventFinishedProcessing).Infof("Finished processed repository")
 }
@@ -4,7 +4,11 @@ import "time"
 
 type Input struct {
 	AdminToken                   string
+	Concurrency                  int
 	DaysToScan                   int
+	Enterprise                   bool
+	EventsPath                   string
+	ExemptionProperty            string
 	EMASSOrg                     string
 	EMASSOrgInstallationID       int64
 	EMASSPromotionAppID          int64
@@ -14,7 +18,9 @@ type Input struct {
 	EMASSSystemListPath          string
 	EMASSSystemListRepo          string
 	Org                          string
+	OrgOverrides                 map[string]map[string]string
 	Repo                         string
+	ReportPath                   string
 }
 
 type EMASSConfig struct {
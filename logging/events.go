@@ -0,0 +1,77 @@
+package logging
+
+type EventType string
+
+const (
+	EventError EventType = "error"
+
+	EventExemptionExpired    EventType = "exemption-expired"
+	EventExemptionInvalid    EventType = "exemption-invalid"
+	EventExemptionUnapproved EventType = "exemption-unapproved"
+	EventSkippedExempt       EventType = "skipped-exempt"
+
+	EventClosedDuplicatePullRequest      EventType = "closed-duplicate-pull-request"
+	EventConvertingCodeQLAction          EventType = "converting-codeql-action"
+	EventDefaultSetupAwaitingAnalysis    EventType = "default-setup-awaiting-analysis"
+	EventDeletedStaleBranch              EventType = "deleted-stale-branch"
+	EventEMASSInventoryNotFound          EventType = "emass-inventory-not-found"
+	EventEnabledSecurityFeature          EventType = "enabled-security-feature"
+	EventFollowUpClosedUnmerged          EventType = "follow-up-closed-unmerged"
+	EventFollowUpEscalated               EventType = "follow-up-escalated"
+	EventFollowUpMerged                  EventType = "follow-up-merged"
+	EventFollowUpOpen                    EventType = "follow-up-open"
+	EventFollowUpReminded                EventType = "follow-up-reminded"
+	EventFollowUpRetryScheduled          EventType = "follow-up-retry-scheduled"
+	EventInstalledVerifyScansApplication EventType = "installed-verify-scans-application"
+	EventMigratedDefaultSetup            EventType = "migrated-default-setup"
+	EventPlanned                         EventType = "planned"
+	EventRebasedPullRequest              EventType = "rebased-pull-request"
+	EventRefreshedPullRequest            EventType = "refreshed-pull-request"
+	EventRepoAlreadyConfigured           EventType = "repo-already-configured"
+	EventRetryingClosedPullRequest       EventType = "retrying-closed-pull-request"
+	EventRollbackFailed                  EventType = "rollback-failed"
+	EventRolledBack                      EventType = "rolled-back"
+	EventSkippedAlreadyConfigured        EventType = "skipped-already-configured"
+	EventSkippedArchived                 EventType = "skipped-archived"
+	EventSkippedDisabled                 EventType = "skipped-disabled"
+	EventSkippedEmpty                    EventType = "skipped-empty"
+	EventSkippedExcludedByPolicy         EventType = "skipped-excluded-by-policy"
+	EventSkippedFork                     EventType = "skipped-fork"
+	EventSkippedIgnored                  EventType = "skipped-ignored"
+	EventSkippedNoSupportedLanguages     EventType = "skipped-no-supported-languages"
+	EventSkippedTemplate                 EventType = "skipped-template"
+	EventSuccessfullyConfigured          EventType = "successfully-configured"
+
+	EventGeneratingEmail       EventType = "generating-email"
+	EventMissingConfiguration  EventType = "missing-configuration"
+	EventMissingData           EventType = "missing-data"
+	EventOutOfDateCLI          EventType = "out-of-date-cli"
+	EventSuccessfullyProcessed EventType = "successfully-processed"
+	EventSystemOwnerNotified   EventType = "system-owner-notified"
+	EventUnapprovedIgnoreFile  EventType = "unapproved-ignore-file"
+
+	EventEMASSJSONNotFound       EventType = "emass-json-not-found"
+	EventFinishedProcessing      EventType = "finished-processing"
+	EventInvalidSystemID         EventType = "invalid-system-id"
+	EventSkippedDatabaseNotFound EventType = "skipped-database-not-found"
+	EventSkippedSARIFNotFound    EventType = "skipped-sarif-not-found"
+	EventSuccessfulUpload        EventType = "successful-upload"
+)
+
+const (
+	DetailAnalysisID       = "analysis_id"
+	DetailApproval         = "approval"
+	DetailApprover         = "approver"
+	DetailAuthor           = "author"
+	DetailCLIVersion       = "cli_version"
+	DetailCommit           = "commit"
+	DetailExemption        = "exemption"
+	DetailExpires          = "expires"
+	DetailFeature          = "feature"
+	DetailLanguage         = "language"
+	DetailMergedBy         = "merged_by"
+	DetailMissingAnalyses  = "missing_analyses"
+	DetailMissingDatabases = "missing_databases"
+	DetailPullRequest      = "pull_request"
+	DetailSystemID         = "system_id"
+)
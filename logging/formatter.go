@@ -0,0 +1,36 @@
+package logging
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	FieldEvent = "event"
+	FieldRepo  = "repo"
+)
+
+type Formatter struct {
+	Org string
+}
+
+func (f *Formatter) Format(entry *log.Entry) ([]byte, error) {
+	if repoValue, ok := entry.Data[FieldRepo]; ok {
+		repo := fmt.Sprint(repoValue)
+		if f.Org != "" {
+			repo = fmt.Sprintf("%s/%s", f.Org, repo)
+		}
+		if eventValue, ok := entry.Data[FieldEvent]; ok {
+			event := fmt.Sprint(eventValue)
+			return []byte(fmt.Sprintf("[%s]: [%s] %s\n", repo, event, entry.Message)), nil
+		}
+		return []byte(fmt.Sprintf("[%s]: %s\n", repo, entry.Message)), nil
+	}
+
+	if f.Org != "" {
+		return []byte(fmt.Sprintf("[%s]: %s\n", f.Org, entry.Message)), nil
+	}
+
+	return []byte(fmt.Sprintf("%s\n", entry.Message)), nil
+}
@@ -0,0 +1,137 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	SchemaVersion = 1
+	StdoutPath    = "stdout"
+)
+
+type Event struct {
+	SchemaVersion int                    `json:"schema_version"`
+	Timestamp     time.Time              `json:"timestamp"`
+	Tool          string                 `json:"tool"`
+	Org           string                 `json:"org"`
+	Repo          string                 `json:"repo"`
+	Event         EventType              `json:"event"`
+	Severity      string                 `json:"severity"`
+	Message       string                 `json:"message"`
+	Details       map[string]interface{} `json:"details"`
+}
+
+type Stream struct {
+	tool   string
+	mutex  sync.Mutex
+	writer io.Writer
+	closer io.Closer
+}
+
+func NewStream(tool, path string) (*Stream, error) {
+	stream := &Stream{
+		tool: tool,
+	}
+	switch path {
+	case "":
+		stream.writer = io.Discard
+		return stream, nil
+	case StdoutPath:
+		stream.writer = os.Stdout
+		return stream, nil
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create event stream: %v", err)
+	}
+	stream.writer = file
+	stream.closer = file
+
+	return stream, nil
+}
+
+func (s *Stream) Hook(org string) log.Hook {
+	return &streamHook{
+		stream: s,
+		org:    org,
+	}
+}
+
+func (s *Stream) Write(event *Event) error {
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %v", err)
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	_, err = s.writer.Write(append(eventBytes, '\n'))
+	if err != nil {
+		return fmt.Errorf("failed to write event: %v", err)
+	}
+
+	return nil
+}
+
+func (s *Stream) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+
+	return s.closer.Close()
+}
+
+func NewEvent(tool, org string, entry *log.Entry) *Event {
+	event := &Event{
+		SchemaVersion: SchemaVersion,
+		Timestamp:     entry.Time.UTC(),
+		Tool:          tool,
+		Org:           org,
+		Event:         EventError,
+		Severity:      entry.Level.String(),
+		Message:       entry.Message,
+		Details:       map[string]interface{}{},
+	}
+	for key, value := range entry.Data {
+		switch key {
+		case FieldRepo:
+			event.Repo = fmt.Sprint(value)
+		case FieldEvent:
+			event.Event = EventType(fmt.Sprint(value))
+		default:
+			if err, ok := value.(error); ok {
+				value = err.Error()
+			}
+			event.Details[key] = value
+		}
+	}
+
+	return event
+}
+
+type streamHook struct {
+	stream *Stream
+	org    string
+}
+
+func (h *streamHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *streamHook) Fire(entry *log.Entry) error {
+	_, hasEvent := entry.Data[FieldEvent]
+	if !hasEvent && entry.Level > log.ErrorLevel {
+		return nil
+	}
+
+	return h.stream.Write(NewEvent(h.stream.tool, h.org, entry))
+}
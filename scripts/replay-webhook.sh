@@ -0,0 +1,45 @@
+#!/usr/bin/env bash
+
+set -euo pipefail
+
+# Check if DEBUG is set to true
+if [[ "${DEBUG:-}" == "true" ]]; then
+  set -x
+fi
+
+if [[ $# -lt 2 ]]; then
+  echo "Usage: $0 <event> <payload.json> [delivery-id]"
+  echo "Example: $0 repository configure-codeql/internal/testdata/webhooks/repository-created.json"
+  exit 1
+fi
+
+event="${1}"
+payload="${2}"
+delivery="${3:-$(uuidgen 2>/dev/null || date +%s%N)}"
+url="${WEBHOOK_URL:-http://localhost:8080/webhook}"
+
+# Check if the payload exists
+if [[ ! -f "${payload}" ]]; then
+  echo "${payload} not found!"
+  exit 1
+fi
+
+# Check if WEBHOOK_SECRET is set
+if [[ -z "${WEBHOOK_SECRET:-}" ]]; then
+  echo "WEBHOOK_SECRET is not set!"
+  exit 1
+fi
+
+signature="sha256=$(openssl dgst -sha256 -hmac "${WEBHOOK_SECRET}" < "${payload}" | sed 's/^.* //')"
+
+echo "Replaying '${event}' webhook delivery ${delivery} to ${url}..."
+curl \
+  -H "Content-Type: application/json" \
+  -H "X-GitHub-Event: ${event}" \
+  -H "X-GitHub-Delivery: ${delivery}" \
+  -H "X-Hub-Signature-256: ${signature}" \
+  --silent \
+  --show-error \
+  --write-out "%{http_code}\n" \
+  --data-binary "@${payload}" \
+  "${url}"
@@ -1,5 +1,9 @@
 package utils
 
+import (
+	"strings"
+)
+
 var SupportedCodeQLLanguages = []string{
 	"c",
 	"cpp",
@@ -22,3 +26,18 @@ func IsSupportedCodeQLLanguage(language string) bool {
 	}
 	return false
 }
+
+var CodeQLLanguageAliases = map[string][]string{
+	"c-cpp":                 {"c", "cpp"},
+	"java-kotlin":           {"java", "kotlin"},
+	"javascript-typescript": {"javascript", "typescript"},
+}
+
+func NormalizeCodeQLLanguage(language string) []string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if languages, ok := CodeQLLanguageAliases[language]; ok {
+		return languages
+	}
+
+	return []string{language}
+}
@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+const (
+	GmailSMTPHost = "smtp.gmail.com"
+	GmailSMTPPort = 587
+)
+
+func SendGmail(from, password, replyTo string, to []string, subject, body string) error {
+	headers := []string{
+		fmt.Sprintf("From: %s", from),
+		fmt.Sprintf("To: %s", strings.Join(to, ",")),
+	}
+	if replyTo != "" {
+		headers = append(headers, fmt.Sprintf("Reply-To: %s", replyTo))
+	}
+	headers = append(headers, fmt.Sprintf("Subject: %s", subject))
+
+	addr := fmt.Sprintf("%s:%d", GmailSMTPHost, GmailSMTPPort)
+	msg := fmt.Sprintf("%s\n\n%s", strings.Join(headers, "\n"), body)
+	auth := smtp.PlainAuth("", from, password, GmailSMTPHost)
+	err := smtp.SendMail(addr, auth, from, to, []byte(msg))
+	if err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/google/go-github/v52/github"
+	"github.com/sethvargo/go-githubactions"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+type OrganizationInstallation struct {
+	Org            string
+	InstallationID int64
+}
+
+func ListOrganizationInstallations(ctx context.Context, appClient *github.Client) ([]*OrganizationInstallation, error) {
+	opts := &github.ListOptions{
+		PerPage: 100,
+	}
+
+	var installations []*OrganizationInstallation
+	for {
+		page, resp, err := appClient.Apps.ListInstallations(ctx, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list app installations: %v", err)
+		}
+		for _, installation := range page {
+			if installation.GetTargetType() != "Organization" || installation.SuspendedAt != nil {
+				continue
+			}
+			installations = append(installations, &OrganizationInstallation{
+				Org:            strings.ToLower(installation.GetAccount().GetLogin()),
+				InstallationID: installation.GetID(),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	sort.Slice(installations, func(i, j int) bool {
+		return installations[i].Org < installations[j].Org
+	})
+
+	return installations, nil
+}
+
+func InstallationIDs(installations []*OrganizationInstallation) map[string]int64 {
+	ids := map[string]int64{}
+	for _, installation := range installations {
+		ids[installation.Org] = installation.InstallationID
+	}
+
+	return ids
+}
+
+func ParseOrgOverrides(content string) (map[string]map[string]string, error) {
+	overrides := map[string]map[string]string{}
+	if strings.TrimSpace(content) == "" {
+		return overrides, nil
+	}
+
+	var parsed map[string]map[string]string
+	err := yaml.Unmarshal([]byte(content), &parsed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal org overrides: %v", err)
+	}
+	for org, inputs := range parsed {
+		normalized := map[string]string{}
+		for input, value := range inputs {
+			input = strings.ToLower(strings.TrimSpace(input))
+			if input == "enterprise" || input == "org" || input == "org_overrides" {
+				return nil, fmt.Errorf("input %s cannot be overridden for org %s", input, org)
+			}
+			normalized[input] = value
+		}
+		overrides[strings.ToLower(org)] = normalized
+	}
+
+	return overrides, nil
+}
+
+func NewInputAction(overrides map[string]string) *githubactions.Action {
+	return githubactions.New(githubactions.WithGetenv(func(key string) string {
+		if strings.HasPrefix(key, "INPUT_") {
+			if value, ok := overrides[strings.ToLower(strings.TrimPrefix(key, "INPUT_"))]; ok {
+				return value
+			}
+		}
+
+		return os.Getenv(key)
+	}))
+}
+
+func OrganizationInputs(overrides map[string]map[string]string, org string, inputs map[string]string) map[string]string {
+	merged := map[string]string{
+		"repo": "",
+	}
+	for input, value := range overrides[org] {
+		merged[input] = value
+	}
+	for input, value := range inputs {
+		merged[input] = value
+	}
+	merged["org"] = org
+
+	return merged
+}
+
+func NewOrganizationLogger(globalLogger *log.Logger, summary *Summary, org string, formatter log.Formatter) (*log.Logger, *Summary) {
+	organizationSummary := NewSummary()
+	logger := log.New()
+	logger.SetLevel(globalLogger.GetLevel())
+	logger.SetFormatter(formatter)
+	logger.AddHook(organizationSummary)
+	logger.AddHook(summary.ForOrganization(org))
+
+	return logger, organizationSummary
+}
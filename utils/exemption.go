@@ -0,0 +1,151 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	ExemptionApproved   = "approved"
+	ExemptionExpired    = "expired"
+	ExemptionInvalid    = "invalid"
+	ExemptionUnapproved = "unapproved"
+)
+
+type CustomProperty struct {
+	PropertyName  string   `json:"property_name"`
+	ValueType     string   `json:"value_type"`
+	AllowedValues []string `json:"allowed_values"`
+}
+
+type Exemption struct {
+	Value    string
+	Approval string
+	Expires  time.Time
+	Status   string
+	Reason   string
+}
+
+type ExemptionChecker struct {
+	Context       context.Context
+	Client        *github.Client
+	Property      string
+	AllowedValues []string
+}
+
+func GetCustomProperty(ctx context.Context, client *github.Client, org, property string) (*CustomProperty, error) {
+	request, err := client.NewRequest(http.MethodGet, fmt.Sprintf("orgs/%s/properties/schema/%s", org, property), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	var customProperty CustomProperty
+	_, err = client.Do(ctx, request, &customProperty)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom property %s: %v", property, err)
+	}
+
+	return &customProperty, nil
+}
+
+func NewExemptionChecker(ctx context.Context, client *github.Client, org, property string) (*ExemptionChecker, error) {
+	customProperty, err := GetCustomProperty(ctx, client, org, property)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExemptionChecker{
+		Context:       ctx,
+		Client:        client,
+		Property:      property,
+		AllowedValues: customProperty.AllowedValues,
+	}, nil
+}
+
+func ParseExemption(value string) (*Exemption, error) {
+	separator := strings.LastIndex(value, ":")
+	if separator < 0 {
+		return nil, fmt.Errorf("exemption '%s' must be formatted as <approval>:<YYYY-MM-DD>", value)
+	}
+
+	approval := strings.TrimSpace(value[:separator])
+	if approval == "" {
+		return nil, fmt.Errorf("exemption '%s' does not reference an approval", value)
+	}
+	expires, err := time.Parse(time.DateOnly, strings.TrimSpace(value[separator+1:]))
+	if err != nil {
+		return nil, fmt.Errorf("exemption '%s' has an invalid expiry date: %v", value, err)
+	}
+
+	return &Exemption{
+		Value:    value,
+		Approval: approval,
+		Expires:  expires,
+	}, nil
+}
+
+func (c *ExemptionChecker) Evaluate(value string, now time.Time) *Exemption {
+	exemption, err := ParseExemption(value)
+	if err != nil {
+		return &Exemption{
+			Value:  value,
+			Status: ExemptionInvalid,
+			Reason: err.Error(),
+		}
+	}
+
+	if !contains(c.AllowedValues, value) {
+		exemption.Status = ExemptionUnapproved
+		exemption.Reason = fmt.Sprintf("'%s' is not an allowed value of custom property %s", value, c.Property)
+		return exemption
+	}
+	if !now.Before(exemption.Expires.AddDate(0, 0, 1)) {
+		exemption.Status = ExemptionExpired
+		exemption.Reason = fmt.Sprintf("exemption %s expired on %s", exemption.Approval, exemption.Expires.Format(time.DateOnly))
+		return exemption
+	}
+	exemption.Status = ExemptionApproved
+
+	return exemption
+}
+
+func (c *ExemptionChecker) Exempt(org, repo string, logger *log.Entry) (bool, error) {
+	properties, err := ListCustomPropertyValues(c.Context, c.Client, org, repo)
+	if err != nil {
+		return false, err
+	}
+
+	for _, value := range properties[c.Property] {
+		exemption := c.Evaluate(value, time.Now().UTC())
+		fields := log.Fields{
+			logging.DetailExemption: exemption.Value,
+		}
+		if exemption.Approval != "" {
+			fields[logging.DetailApproval] = exemption.Approval
+			fields[logging.DetailExpires] = exemption.Expires.Format(time.DateOnly)
+		}
+
+		switch exemption.Status {
+		case ExemptionApproved:
+			fields["event"] = logging.EventSkippedExempt
+			logger.WithFields(fields).Infof("Repository is exempt until %s by approval %s, skipping", exemption.Expires.Format(time.DateOnly), exemption.Approval)
+			return true, nil
+		case ExemptionExpired:
+			fields["event"] = logging.EventExemptionExpired
+		case ExemptionUnapproved:
+			fields["event"] = logging.EventExemptionUnapproved
+		default:
+			fields["event"] = logging.EventExemptionInvalid
+		}
+		logger.WithFields(fields).Warnf("Ignoring exemption: %s", exemption.Reason)
+	}
+
+	return false, nil
+}
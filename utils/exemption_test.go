@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestExemptionCheckerEvaluate(t *testing.T) {
+	checker := &ExemptionChecker{
+		Property:      "codeql-exemption",
+		AllowedValues: []string{"CHG0012345:2024-12-31", "RITM:42:2024-06-30", "CHG0099999:2023-01-01", "CHG1:someday"},
+	}
+	now := time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		value    string
+		status   string
+		approval string
+	}{
+		{name: "approved on the last day", value: "CHG0012345:2024-12-31", status: ExemptionApproved, approval: "CHG0012345"},
+		{name: "approval containing a colon", value: "RITM:42:2024-06-30", status: ExemptionExpired, approval: "RITM:42"},
+		{name: "expired", value: "CHG0099999:2023-01-01", status: ExemptionExpired, approval: "CHG0099999"},
+		{name: "not an allowed value", value: "CHG0054321:2025-12-31", status: ExemptionUnapproved, approval: "CHG0054321"},
+		{name: "missing expiry", value: "CHG0012345", status: ExemptionInvalid},
+		{name: "missing approval", value: ":2025-12-31", status: ExemptionInvalid},
+		{name: "invalid expiry", value: "CHG1:someday", status: ExemptionInvalid},
+		{name: "empty", value: "", status: ExemptionInvalid},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			exemption := checker.Evaluate(test.value, now)
+			if exemption.Status != test.status {
+				t.Fatalf("got status %s, want %s: %s", exemption.Status, test.status, exemption.Reason)
+			}
+			if exemption.Approval != test.approval {
+				t.Errorf("got approval %s, want %s", exemption.Approval, test.approval)
+			}
+			if test.status != ExemptionApproved && exemption.Reason == "" {
+				t.Error("expected a reason")
+			}
+		})
+	}
+}
+
+func TestExemptionCheckerEvaluateExpiresAfterLastDay(t *testing.T) {
+	checker := &ExemptionChecker{AllowedValues: []string{"CHG0012345:2024-12-31"}}
+
+	exemption := checker.Evaluate("CHG0012345:2024-12-31", time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	if exemption.Status != ExemptionExpired {
+		t.Errorf("got status %s, want %s", exemption.Status, ExemptionExpired)
+	}
+}
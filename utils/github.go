@@ -15,13 +15,14 @@ func NewGitHubClient(token string) *github.Client {
 	ts := oauth2.StaticTokenSource(
 		&oauth2.Token{AccessToken: token},
 	)
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: NewRateLimitTransport(http.DefaultTransport)})
 	tc := oauth2.NewClient(ctx, ts)
 
 	return github.NewClient(tc)
 }
 
 func NewGitHubAppClient(appID int64, privateKey []byte) (*github.Client, error) {
-	itr, err := ghinstallation.NewAppsTransport(http.DefaultTransport, appID, privateKey)
+	itr, err := ghinstallation.NewAppsTransport(NewRateLimitTransport(http.DefaultTransport), appID, privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub App transport: %v", err)
 	}
@@ -30,7 +31,7 @@ func NewGitHubAppClient(appID int64, privateKey []byte) (*github.Client, error)
 }
 
 func NewGitHubInstallationClient(appID int64, installationID int64, privateKey []byte) (*github.Client, error) {
-	itr, err := ghinstallation.New(http.DefaultTransport, appID, installationID, privateKey)
+	itr, err := ghinstallation.New(NewRateLimitTransport(http.DefaultTransport), appID, installationID, privateKey)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub App transport: %v", err)
 	}
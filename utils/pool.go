@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"sync"
+)
+
+func ProcessConcurrently[T any](items []T, concurrency int, process func(T)) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	queue := make(chan T)
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range queue {
+				process(item)
+			}
+		}()
+	}
+
+	for _, item := range items {
+		queue <- item
+	}
+	close(queue)
+	wg.Wait()
+}
@@ -0,0 +1,41 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v52/github"
+)
+
+type CustomPropertyValue struct {
+	PropertyName string      `json:"property_name"`
+	Value        interface{} `json:"value"`
+}
+
+func ListCustomPropertyValues(ctx context.Context, client *github.Client, owner, repo string) (map[string][]string, error) {
+	request, err := client.NewRequest(http.MethodGet, fmt.Sprintf("repos/%s/%s/properties/values", owner, repo), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	var values []CustomPropertyValue
+	_, err = client.Do(ctx, request, &values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list custom property values: %v", err)
+	}
+
+	properties := map[string][]string{}
+	for _, value := range values {
+		switch v := value.Value.(type) {
+		case string:
+			properties[value.PropertyName] = []string{v}
+		case []interface{}:
+			for _, item := range v {
+				properties[value.PropertyName] = append(properties[value.PropertyName], fmt.Sprint(item))
+			}
+		}
+	}
+
+	return properties, nil
+}
@@ -0,0 +1,115 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	RateLimitThreshold  = 100
+	rateLimitMaxRetries = 3
+)
+
+type RateLimitTransport struct {
+	Base      http.RoundTripper
+	Threshold int
+
+	mutex    sync.Mutex
+	resumeAt time.Time
+}
+
+func NewRateLimitTransport(base http.RoundTripper) *RateLimitTransport {
+	return &RateLimitTransport{
+		Base:      base,
+		Threshold: RateLimitThreshold,
+	}
+}
+
+func (t *RateLimitTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		err := t.wait(request)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := t.Base.RoundTrip(request)
+		if err != nil {
+			return nil, err
+		}
+
+		limited := t.observe(response)
+		if !limited || attempt >= rateLimitMaxRetries || (request.Body != nil && request.GetBody == nil) {
+			return response, nil
+		}
+		response.Body.Close()
+
+		request = request.Clone(request.Context())
+		if request.GetBody != nil {
+			request.Body, err = request.GetBody()
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (t *RateLimitTransport) wait(request *http.Request) error {
+	t.mutex.Lock()
+	delay := time.Until(t.resumeAt)
+	t.mutex.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-request.Context().Done():
+		return request.Context().Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (t *RateLimitTransport) observe(response *http.Response) bool {
+	if response.StatusCode == http.StatusForbidden || response.StatusCode == http.StatusTooManyRequests {
+		if retryAfter, err := strconv.Atoi(response.Header.Get("Retry-After")); err == nil {
+			t.pause(time.Now().Add(time.Duration(retryAfter)*time.Second), "secondary rate limit exceeded")
+			return true
+		}
+	}
+
+	remaining, err := strconv.Atoi(response.Header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return false
+	}
+	reset, err := strconv.ParseInt(response.Header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return false
+	}
+
+	if remaining == 0 && response.StatusCode == http.StatusForbidden {
+		t.pause(time.Unix(reset, 0).Add(time.Second), "primary rate limit exceeded")
+		return true
+	}
+	if remaining < t.Threshold {
+		t.pause(time.Unix(reset, 0).Add(time.Second), "primary rate limit running low")
+	}
+
+	return false
+}
+
+func (t *RateLimitTransport) pause(until time.Time, reason string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !until.After(t.resumeAt) {
+		return
+	}
+	t.resumeAt = until
+	log.Warnf("GitHub %s, pausing requests until %s", reason, until.Format(time.RFC3339))
+}
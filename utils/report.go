@@ -0,0 +1,189 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	StepSummaryEnv = "GITHUB_STEP_SUMMARY"
+)
+
+type Report struct {
+	Tool          string                `json:"tool"`
+	GeneratedAt   time.Time             `json:"generated_at"`
+	Repositories  int                   `json:"repositories"`
+	Failed        int                   `json:"failed"`
+	Events        map[string]int        `json:"events"`
+	Organizations []*OrganizationReport `json:"organizations,omitempty"`
+	Results       []*RepositoryResult   `json:"results"`
+}
+
+type OrganizationReport struct {
+	Org          string `json:"org"`
+	Repositories int    `json:"repositories"`
+	Failed       int    `json:"failed"`
+	Error        bool   `json:"error"`
+}
+
+func (s *Summary) Report(tool string) *Report {
+	results := s.Results()
+	if results == nil {
+		results = []*RepositoryResult{}
+	}
+
+	return &Report{
+		Tool:         tool,
+		GeneratedAt:  time.Now().UTC(),
+		Repositories: len(results),
+		Failed:       len(s.Failed()),
+		Events:       s.EventCounts(),
+		Results:      results,
+	}
+}
+
+func (r *Report) AddOrganizations(organizations map[string]*Summary, failed []string) {
+	for org, summary := range organizations {
+		r.Organizations = append(r.Organizations, &OrganizationReport{
+			Org:          org,
+			Repositories: len(summary.Results()),
+			Failed:       len(summary.Failed()),
+			Error:        contains(failed, org),
+		})
+	}
+	sort.Slice(r.Organizations, func(i, j int) bool {
+		return r.Organizations[i].Org < r.Organizations[j].Org
+	})
+}
+
+func (r *Report) Markdown() string {
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("## %s\n\n", r.Tool))
+	builder.WriteString(fmt.Sprintf("Processed %d repositories, %d failed.\n", r.Repositories, r.Failed))
+
+	if len(r.Organizations) > 0 {
+		builder.WriteString("\n### Organizations\n\n")
+		builder.WriteString("| Organization | Repositories | Failed |\n")
+		builder.WriteString("|--------------|--------------|--------|\n")
+		for _, org := range r.Organizations {
+			failed := fmt.Sprint(org.Failed)
+			if org.Error {
+				failed = "organization failed"
+			}
+			builder.WriteString(fmt.Sprintf("| %s | %d | %s |\n", org.Org, org.Repositories, failed))
+		}
+	}
+
+	var events []string
+	for event := range r.Events {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	if len(events) > 0 {
+		builder.WriteString("\n### Events\n\n")
+		builder.WriteString("| Event | Count |\n")
+		builder.WriteString("|-------|-------|\n")
+		for _, event := range events {
+			builder.WriteString(fmt.Sprintf("| `%s` | %d |\n", event, r.Events[event]))
+		}
+	}
+
+	if len(r.Results) > 0 {
+		builder.WriteString("\n### Repositories\n\n")
+		builder.WriteString("| Repository | Events | Errors |\n")
+		builder.WriteString("|------------|--------|--------|\n")
+		for _, result := range r.Results {
+			var events []string
+			for _, event := range result.Events {
+				events = append(events, fmt.Sprintf("`%s`", event))
+			}
+			var errors []string
+			for _, message := range result.Errors {
+				errors = append(errors, markdownCell(message))
+			}
+			builder.WriteString(fmt.Sprintf("| %s | %s | %s |\n", result.Repository, strings.Join(events, " "), strings.Join(errors, "<br>")))
+		}
+	}
+
+	return builder.String()
+}
+
+func (r *Report) WriteJSON(path string) error {
+	reportBytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %v", err)
+	}
+
+	err = os.WriteFile(path, reportBytes, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write report: %v", err)
+	}
+
+	return nil
+}
+
+func WriteStepSummary(markdown string) (bool, error) {
+	path := os.Getenv(StepSummaryEnv)
+	if path == "" {
+		return false, nil
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return false, fmt.Errorf("failed to open step summary: %v", err)
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(markdown + "\n")
+	if err != nil {
+		return false, fmt.Errorf("failed to write step summary: %v", err)
+	}
+
+	return true, nil
+}
+
+func PublishReport(logger *log.Logger, report *Report, path string) {
+	logger.Infof("Writing step summary")
+	written, err := WriteStepSummary(report.Markdown())
+	if err != nil {
+		logger.Warnf("Failed to write step summary: %v", err)
+	} else if written {
+		logger.Debugf("Step summary written")
+	} else {
+		logger.Debugf("%s is not set, skipping step summary", StepSummaryEnv)
+	}
+
+	if path == "" {
+		return
+	}
+	logger.Infof("Writing report for %d repositories to %s", report.Repositories, path)
+	err = report.WriteJSON(path)
+	if err != nil {
+		logger.Warnf("Failed to write report: %v", err)
+		return
+	}
+	logger.Debugf("Report written")
+}
+
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\r", "")
+
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
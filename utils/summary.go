@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	log "github.com/sirupsen/logrus"
+)
+
+type Summary struct {
+	mutex   sync.Mutex
+	results map[string]*RepositoryResult
+}
+
+type RepositoryResult struct {
+	Repository string   `json:"repository"`
+	Events     []string `json:"events"`
+	Errors     []string `json:"errors"`
+}
+
+func NewSummary() *Summary {
+	return &Summary{
+		results: map[string]*RepositoryResult{},
+	}
+}
+
+func (s *Summary) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (s *Summary) Fire(entry *log.Entry) error {
+	repoValue, ok := entry.Data[logging.FieldRepo]
+	if !ok {
+		return nil
+	}
+	s.record(fmt.Sprint(repoValue), entry)
+
+	return nil
+}
+
+func (s *Summary) ForOrganization(org string) log.Hook {
+	return &organizationHook{
+		summary: s,
+		org:     org,
+	}
+}
+
+func (s *Summary) record(repo string, entry *log.Entry) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	result, ok := s.results[repo]
+	if !ok {
+		result = &RepositoryResult{
+			Repository: repo,
+			Events:     []string{},
+			Errors:     []string{},
+		}
+		s.results[repo] = result
+	}
+	if eventValue, ok := entry.Data[logging.FieldEvent]; ok {
+		result.Events = append(result.Events, fmt.Sprint(eventValue))
+	}
+	if entry.Level <= log.ErrorLevel {
+		result.Errors = append(result.Errors, entry.Message)
+	}
+}
+
+func (s *Summary) Results() []*RepositoryResult {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var results []*RepositoryResult
+	for _, result := range s.results {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Repository < results[j].Repository
+	})
+
+	return results
+}
+
+func (s *Summary) EventCounts() map[string]int {
+	counts := map[string]int{}
+	for _, result := range s.Results() {
+		for _, event := range result.Events {
+			counts[event]++
+		}
+	}
+
+	return counts
+}
+
+func (s *Summary) Failed() []string {
+	var failed []string
+	for _, result := range s.Results() {
+		if len(result.Errors) > 0 {
+			failed = append(failed, result.Repository)
+		}
+	}
+
+	return failed
+}
+
+func (s *Summary) Log(logger *log.Logger) {
+	results := s.Results()
+	failed := s.Failed()
+	logger.Infof("Processed %d repositories, %d failed", len(results), len(failed))
+
+	counts := s.EventCounts()
+	var events []string
+	for event := range counts {
+		events = append(events, event)
+	}
+	sort.Strings(events)
+	for _, event := range events {
+		logger.Infof("%s: %d", event, counts[event])
+	}
+
+	for _, repo := range failed {
+		logger.Warnf("Failed to process repository: %s", repo)
+	}
+}
+
+type organizationHook struct {
+	summary *Summary
+	org     string
+}
+
+func (h *organizationHook) Levels() []log.Level {
+	return log.AllLevels
+}
+
+func (h *organizationHook) Fire(entry *log.Entry) error {
+	repoValue, ok := entry.Data[logging.FieldRepo]
+	if !ok {
+		return nil
+	}
+	h.summary.record(fmt.Sprintf("%s/%s", h.org, repoValue), entry)
+
+	return nil
+}
+
+func LogOrganizations(logger *log.Logger, organizations map[string]*Summary, failed []string) {
+	var orgs []string
+	for org := range organizations {
+		orgs = append(orgs, org)
+	}
+	sort.Strings(orgs)
+	for _, org := range orgs {
+		logger.Infof("Organization %s: processed %d repositories, %d failed", org, len(organizations[org].Results()), len(organizations[org].Failed()))
+	}
+
+	for _, org := range failed {
+		logger.Warnf("Failed to process organization: %s", org)
+	}
+}
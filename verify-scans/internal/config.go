@@ -5,7 +5,7 @@ import (
 	"strconv"
 	"strings"
 
-	"github.com/sethvargo/go-githubactions"
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
 )
 
 var (
@@ -13,130 +13,177 @@ var (
 )
 
 func ParseInput() *Input {
-	adminToken := githubactions.GetInput("admin_token")
+	return ParseInputWithOverrides(nil)
+}
+
+func ParseInputWithOverrides(overrides map[string]string) *Input {
+	action := utils.NewInputAction(overrides)
+
+	enterprise := strings.ToLower(strings.TrimSpace(action.GetInput("enterprise"))) == "true"
+
+	adminToken := action.GetInput("admin_token")
 	if adminToken == "" {
-		githubactions.Fatalf("admin_token input is required")
+		action.Fatalf("admin_token input is required")
 	}
 
-	daysToScanString := githubactions.GetInput("days_to_scan")
+	concurrencyString := action.GetInput("concurrency")
+	if concurrencyString == "" {
+		concurrencyString = "1"
+	}
+	concurrency, err := strconv.Atoi(concurrencyString)
+	if err != nil || concurrency < 1 {
+		action.Fatalf("concurrency input must be a positive integer")
+	}
+
+	daysToScanString := action.GetInput("days_to_scan")
 	if daysToScanString == "" {
-		githubactions.Fatalf("days_to_scan input is required")
+		action.Fatalf("days_to_scan input is required")
 	}
 	daysToScan, err := strconv.Atoi(daysToScanString)
 	if err != nil {
-		githubactions.Fatalf("days_to_scan input must be an integer")
+		action.Fatalf("days_to_scan input must be an integer")
 	}
 
-	emassPromotionAppID := githubactions.GetInput("emass_promotion_app_id")
+	emassPromotionAppID := action.GetInput("emass_promotion_app_id")
 	if emassPromotionAppID == "" {
-		githubactions.Fatalf("emass_promotion_app_id input is required")
+		action.Fatalf("emass_promotion_app_id input is required")
 	}
 
-	emassPromotionPrivateKey := githubactions.GetInput("emass_promotion_private_key")
+	emassPromotionPrivateKey := action.GetInput("emass_promotion_private_key")
 	if emassPromotionPrivateKey == "" {
-		githubactions.Fatalf("emass_promotion_private_key input is required")
+		action.Fatalf("emass_promotion_private_key input is required")
 	}
 
-	emassPromotionInstallationID := githubactions.GetInput("emass_promotion_installation_id")
-	if emassPromotionInstallationID == "" {
-		githubactions.Fatalf("emass_promotion_installation_id input is required")
+	emassPromotionInstallationID := action.GetInput("emass_promotion_installation_id")
+	if emassPromotionInstallationID == "" && !enterprise {
+		action.Fatalf("emass_promotion_installation_id input is required")
 	}
 
-	emassSystemListRepo := githubactions.GetInput("emass_system_list_repo")
+	emassSystemListRepo := action.GetInput("emass_system_list_repo")
 	if emassSystemListRepo == "" {
-		githubactions.Fatalf("emass_system_list_repo input is required")
+		action.Fatalf("emass_system_list_repo input is required")
 	}
 
-	emassSystemListPath := githubactions.GetInput("emass_system_list_path")
+	emassSystemListPath := action.GetInput("emass_system_list_path")
 	if emassSystemListPath == "" {
-		githubactions.Fatalf("emass_system_list_path input is required")
+		action.Fatalf("emass_system_list_path input is required")
 	}
 
-	gmailFrom := githubactions.GetInput("gmail_from")
+	gmailFrom := action.GetInput("gmail_from")
 	if gmailFrom == "" {
-		githubactions.Fatalf("gmail_from input is required")
+		action.Fatalf("gmail_from input is required")
 	}
 
-	gmailUser := githubactions.GetInput("gmail_user")
+	gmailUser := action.GetInput("gmail_user")
 	if gmailUser == "" {
-		githubactions.Fatalf("gmail_user input is required")
+		action.Fatalf("gmail_user input is required")
 	}
 
-	gmailPassword := githubactions.GetInput("gmail_password")
+	gmailPassword := action.GetInput("gmail_password")
 	if gmailPassword == "" {
-		githubactions.Fatalf("gmail_password input is required")
+		action.Fatalf("gmail_password input is required")
 	}
 
-	missingInfoEmailTemplate := githubactions.GetInput("missing_info_email_template")
+	missingInfoEmailTemplate := action.GetInput("missing_info_email_template")
 	if missingInfoEmailTemplate == "" {
-		githubactions.Fatalf("missing_info_email_template input is required")
+		action.Fatalf("missing_info_email_template input is required")
 	}
 
-	missingInfoIssueTemplate := githubactions.GetInput("missing_info_issue_template")
+	missingInfoIssueTemplate := action.GetInput("missing_info_issue_template")
 	if missingInfoIssueTemplate == "" {
-		githubactions.Fatalf("missing_info_issue_template input is required")
+		action.Fatalf("missing_info_issue_template input is required")
 	}
 
-	nonCompliantEmailTemplate := githubactions.GetInput("non_compliant_email_template")
+	nonCompliantEmailTemplate := action.GetInput("non_compliant_email_template")
 	if nonCompliantEmailTemplate == "" {
-		githubactions.Fatalf("non_compliant_email_template input is required")
+		action.Fatalf("non_compliant_email_template input is required")
+	}
+
+	org := action.GetInput("org")
+	if org == "" && !enterprise {
+		action.Fatalf("org input is required")
 	}
 
-	org := githubactions.GetInput("org")
-	if org == "" {
-		githubactions.Fatalf("org input is required")
+	orgOverrides, err := utils.ParseOrgOverrides(action.GetInput("org_overrides"))
+	if err != nil {
+		action.Fatalf("invalid org_overrides input: %v", err)
 	}
 
-	outOfComplianceCLIEmailTemplate := githubactions.GetInput("out_of_compliance_cli_email_template")
+	outOfComplianceCLIEmailTemplate := action.GetInput("out_of_compliance_cli_email_template")
 	if outOfComplianceCLIEmailTemplate == "" {
-		githubactions.Fatalf("out_of_compliance_cli_email_template input is required")
+		action.Fatalf("out_of_compliance_cli_email_template input is required")
 	}
 
-	repo := githubactions.GetInput("repo")
+	repo := action.GetInput("repo")
+
+	reportPath := action.GetInput("report_path")
+	if reportPath == "" {
+		reportPath = "report.json"
+	}
+
+	eventsPath := action.GetInput("events_path")
+
+	exemptionProperty := strings.TrimSpace(action.GetInput("exemption_property"))
 
-	secondaryEmail := githubactions.GetInput("secondary_email")
+	ignoreApproversRepo := strings.TrimSpace(action.GetInput("ignore_approvers_repo"))
+	ignoreApproversPath := action.GetInput("ignore_approvers_path")
+	if ignoreApproversRepo != "" && ignoreApproversPath == "" {
+		action.Fatalf("ignore_approvers_path input is required when ignore_approvers_repo is set")
+	}
+
+	secondaryEmail := action.GetInput("secondary_email")
 	if secondaryEmail == "" {
-		githubactions.Fatalf("secondary_email input is required")
+		action.Fatalf("secondary_email input is required")
 	}
 
-	verifyScansAppID := githubactions.GetInput("verify_scans_app_id")
+	verifyScansAppID := action.GetInput("verify_scans_app_id")
 	if verifyScansAppID == "" {
-		githubactions.Fatalf("verify_scans_app_id input is required")
+		action.Fatalf("verify_scans_app_id input is required")
 	}
 
-	verifyScansPrivateKey := githubactions.GetInput("verify_scans_private_key")
+	verifyScansPrivateKey := action.GetInput("verify_scans_private_key")
 	if verifyScansPrivateKey == "" {
-		githubactions.Fatalf("verify_scans_private_key input is required")
+		action.Fatalf("verify_scans_private_key input is required")
 	}
 
-	verifyScansInstallationID := githubactions.GetInput("verify_scans_installation_id")
-	if verifyScansInstallationID == "" {
-		githubactions.Fatalf("verify_scans_installation_id input is required")
+	verifyScansInstallationID := action.GetInput("verify_scans_installation_id")
+	if verifyScansInstallationID == "" && !enterprise {
+		action.Fatalf("verify_scans_installation_id input is required")
 	}
 
 	emassPromotionAppIDInt64, err := strconv.ParseInt(emassPromotionAppID, 10, 64)
 	if err != nil {
-		githubactions.Fatalf("emass_promotion_app_id input must be an integer")
+		action.Fatalf("emass_promotion_app_id input must be an integer")
 	}
 
-	emassPromotionInstallationIDInt64, err := strconv.ParseInt(emassPromotionInstallationID, 10, 64)
-	if err != nil {
-		githubactions.Fatalf("emass_promotion_installation_id input must be an integer")
+	var emassPromotionInstallationIDInt64 int64
+	if emassPromotionInstallationID != "" {
+		emassPromotionInstallationIDInt64, err = strconv.ParseInt(emassPromotionInstallationID, 10, 64)
+		if err != nil {
+			action.Fatalf("emass_promotion_installation_id input must be an integer")
+		}
 	}
 
 	verifyScansAppIDInt64, err := strconv.ParseInt(verifyScansAppID, 10, 64)
 	if err != nil {
-		githubactions.Fatalf("verify_scans_app_id input must be an integer")
+		action.Fatalf("verify_scans_app_id input must be an integer")
 	}
 
-	verifyScansInstallationIDInt64, err := strconv.ParseInt(verifyScansInstallationID, 10, 64)
-	if err != nil {
-		githubactions.Fatalf("verify_scans_installation_id input must be an integer")
+	var verifyScansInstallationIDInt64 int64
+	if verifyScansInstallationID != "" {
+		verifyScansInstallationIDInt64, err = strconv.ParseInt(verifyScansInstallationID, 10, 64)
+		if err != nil {
+			action.Fatalf("verify_scans_installation_id input must be an integer")
+		}
 	}
 
 	return &Input{
 		AdminToken:                      adminToken,
+		Concurrency:                     concurrency,
 		DaysToScan:                      daysToScan,
+		Enterprise:                      enterprise,
+		EventsPath:                      eventsPath,
+		ExemptionProperty:               exemptionProperty,
 		EMASSPromotionAppID:             emassPromotionAppIDInt64,
 		EMASSPromotionPrivateKey:        []byte(emassPromotionPrivateKey),
 		EMASSPromotionInstallationID:    emassPromotionInstallationIDInt64,
@@ -145,12 +192,16 @@ func ParseInput() *Input {
 		GmailFrom:                       gmailFrom,
 		GmailUser:                       gmailUser,
 		GmailPassword:                   gmailPassword,
+		IgnoreApproversPath:             ignoreApproversPath,
+		IgnoreApproversRepo:             strings.ToLower(ignoreApproversRepo),
 		MissingInfoEmailTemplate:        missingInfoEmailTemplate,
 		MissingInfoIssueTemplate:        missingInfoIssueTemplate,
 		NonCompliantEmailTemplate:       nonCompliantEmailTemplate,
 		Org:                             strings.ToLower(org),
+		OrgOverrides:                    orgOverrides,
 		OutOfComplianceCLIEmailTemplate: outOfComplianceCLIEmailTemplate,
 		Repo:                            strings.ToLower(repo),
+		ReportPath:                      reportPath,
 		SecondaryEmail:                  secondaryEmail,
 		VerifyScansAppID:                verifyScansAppIDInt64,
 		VerifyScansPrivateKey:           []byte(verifyScansPrivateKey),
@@ -4,11 +4,12 @@ import (
 	"fmt"
 
 	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
 )
 
-func (m *Manager) CreateIssue(owner, repo, title, body string, labels []string) error {
+func (m *Manager) CreateIssue(owner, repo, title, body string, labels []string, logger *log.Entry) error {
 	if DisableNotifications {
-		m.Logger.Warnf("notifications are disabled, skipping creating issue")
+		logger.Warnf("notifications are disabled, skipping creating issue")
 		return nil
 	}
 	request := &github.IssueRequest{
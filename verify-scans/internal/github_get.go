@@ -13,7 +13,7 @@ import (
 func (m *Manager) GetEMASSConfig(owner, repo, path string) (*EMASSConfig, error) {
 	content, _, resp, err := m.VerifyScansGithubClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return nil, nil
 		}
 
@@ -37,7 +37,7 @@ func (m *Manager) GetEMASSConfig(owner, repo, path string) (*EMASSConfig, error)
 func (m *Manager) GetCodeQLConfig(owner, repo, path string) (*CodeQLConfig, error) {
 	content, _, resp, err := m.VerifyScansGithubClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return &CodeQLConfig{
 				BuildCommands:     map[string]string{},
 				ExcludedLanguages: []string{},
@@ -64,7 +64,7 @@ func (m *Manager) GetCodeQLConfig(owner, repo, path string) (*CodeQLConfig, erro
 func (m *Manager) GetEMASSSystemList(owner, repo, path string) ([]int64, error) {
 	content, _, resp, err := m.AdminGitHubClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return nil, fmt.Errorf("file not found")
 		}
 
@@ -93,6 +93,51 @@ func (m *Manager) GetEMASSSystemList(owner, repo, path string) ([]int64, error)
 	return ids, nil
 }
 
+func (m *Manager) GetIgnoreApprovers(owner, repo, path string) ([]string, error) {
+	content, _, resp, err := m.AdminGitHubClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("file not found")
+		}
+
+		return nil, fmt.Errorf("failed to get file: %v", err)
+	}
+
+	decodedContent, err := content.GetContent()
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode file content: %v", err)
+	}
+
+	var approvers []string
+	lines := strings.Split(strings.TrimSpace(decodedContent), "\n")
+	for _, line := range lines {
+		trimmedLine := strings.TrimSpace(line)
+		if trimmedLine != "" && !strings.HasPrefix(trimmedLine, "#") {
+			approvers = append(approvers, strings.ToLower(strings.TrimPrefix(trimmedLine, "@")))
+		}
+	}
+
+	return approvers, nil
+}
+
+func (m *Manager) GetCommit(owner, repo, sha string) (*github.RepositoryCommit, error) {
+	commit, _, err := m.AdminGitHubClient.Repositories.GetCommit(m.Context, owner, repo, sha, &github.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit %s: %v", sha, err)
+	}
+
+	return commit, nil
+}
+
+func (m *Manager) GetPullRequest(owner, repo string, number int) (*github.PullRequest, error) {
+	pullRequest, _, err := m.AdminGitHubClient.PullRequests.Get(m.Context, owner, repo, number)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pull request #%d: %v", number, err)
+	}
+
+	return pullRequest, nil
+}
+
 func (m *Manager) GetLatestCodeQLVersions() ([]string, error) {
 	opts := &github.ListOptions{
 		PerPage: 5,
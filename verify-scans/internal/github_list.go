@@ -11,6 +11,7 @@ import (
 
 	"github.com/department-of-veterans-affairs/codeql-tools/utils"
 	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
 )
 
 func (m *Manager) ListRepos() ([]*github.Repository, error) {
@@ -36,6 +37,59 @@ func (m *Manager) ListRepos() ([]*github.Repository, error) {
 	return repos, nil
 }
 
+func (m *Manager) ListFileCommits(owner, repo, branch, path string) ([]*github.RepositoryCommit, error) {
+	opts := &github.CommitsListOptions{
+		SHA:  branch,
+		Path: path,
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var commits []*github.RepositoryCommit
+	for {
+		page, resp, err := m.AdminGitHubClient.Repositories.ListCommits(m.Context, owner, repo, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list commits: %v", err)
+		}
+		commits = append(commits, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return commits, nil
+}
+
+func (m *Manager) ListPullRequestsWithCommit(owner, repo, sha string) ([]*github.PullRequest, error) {
+	opts := &github.PullRequestListOptions{
+		State: "all",
+		ListOptions: github.ListOptions{
+			PerPage: 100,
+		},
+	}
+
+	var pullRequests []*github.PullRequest
+	for {
+		page, resp, err := m.AdminGitHubClient.PullRequests.ListPullRequestsWithCommit(m.Context, owner, repo, sha, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests with commit %s: %v", sha, err)
+		}
+		pullRequests = append(pullRequests, page...)
+
+		if resp.NextPage == 0 {
+			break
+		}
+
+		opts.Page = resp.NextPage
+	}
+
+	return pullRequests, nil
+}
+
 func (m *Manager) ListExpectedCodeQLLanguages(owner, repo string, ignoredLanguages []string) ([]string, error) {
 	languages, _, err := m.VerifyScansGithubClient.Repositories.ListLanguages(m.Context, owner, repo)
 	if err != nil {
@@ -85,7 +139,7 @@ func (m *Manager) ListCodeQLDatabaseLanguages(owner, repo string) ([]string, err
 	return languages, nil
 }
 
-func (m *Manager) ListCodeQLAnalyses(owner, repo, branch string, requiredLanguages []string) (*Analyses, error) {
+func (m *Manager) ListCodeQLAnalyses(owner, repo, branch string, requiredLanguages []string, logger *log.Entry) (*Analyses, error) {
 	page := 0
 	results := &Analyses{}
 	endpoint := "https://api.github.com/repos/%s/%s/code-scanning/analyses?per_page=100&page=%d"
@@ -146,7 +200,7 @@ func (m *Manager) ListCodeQLAnalyses(owner, repo, branch string, requiredLanguag
 				}
 				complete := AllRequiredAnalysesFound(results.Languages, requiredLanguages)
 				if complete {
-					m.Logger.Infof("Found all required analyses, stopping search")
+					logger.Infof("Found all required analyses, stopping search")
 					return results, nil
 				}
 			}
@@ -9,12 +9,13 @@ import (
 	"time"
 
 	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
 )
 
 func (m *Manager) FileExists(owner, repo, path string) (bool, error) {
 	_, _, resp, err := m.VerifyScansGithubClient.Repositories.GetContents(m.Context, owner, repo, path, &github.RepositoryContentGetOptions{})
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return false, nil
 		}
 
@@ -101,7 +102,7 @@ func AllRequiredAnalysesFound(languages, requiredLanguages []string) bool {
 func (m *Manager) EMASSAppInstalled(owner, repo string) (bool, error) {
 	_, resp, err := m.EMASSGithubClient.Apps.FindRepositoryInstallation(m.Context, owner, repo)
 	if err != nil {
-		if resp.StatusCode == http.StatusNotFound {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
 			return false, nil
 		}
 
@@ -111,9 +112,9 @@ func (m *Manager) EMASSAppInstalled(owner, repo string) (bool, error) {
 	return true, nil
 }
 
-func (m *Manager) SendEmail(emailAddress, subjectContent, body string) error {
+func (m *Manager) SendEmail(emailAddress, subjectContent, body string, logger *log.Entry) error {
 	if DisableNotifications {
-		m.Logger.Warnf("notifications are disabled, skipping sending email")
+		logger.Warnf("notifications are disabled, skipping sending email")
 		return nil
 	}
 
@@ -205,13 +206,13 @@ func (m *Manager) IssuesExists(owner, repo, label string) (bool, error) {
 	return len(issues) > 0, nil
 }
 
-func (m *Manager) CloseIssues(owner, repo string, issueNumbers []int) {
+func (m *Manager) CloseIssues(owner, repo string, issueNumbers []int, logger *log.Entry) {
 	for _, number := range issueNumbers {
 		_, _, err := m.VerifyScansGithubClient.Issues.Edit(m.Context, owner, repo, number, &github.IssueRequest{
 			State: github.String("closed"),
 		})
 		if err != nil {
-			m.Logger.Errorf("failed to close issue: %v", err)
+			logger.Errorf("failed to close issue: %v", err)
 		}
 	}
 }
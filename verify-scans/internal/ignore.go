@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/google/go-github/v52/github"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	IgnoreFilePath = ".github/.emass-repo-ignore"
+)
+
+type IgnoreFileAttribution struct {
+	Commit   string
+	Author   string
+	Verified bool
+	MergedBy string
+	Approver string
+}
+
+func (m *Manager) IgnoreApproversLocation() (string, string) {
+	owner, repo, found := strings.Cut(m.Config.IgnoreApproversRepo, "/")
+	if !found {
+		return m.Config.Org, owner
+	}
+
+	return owner, repo
+}
+
+func (m *Manager) GetIgnoreFileCommit(owner, repo, branch string) (*github.RepositoryCommit, error) {
+	commits, err := m.ListFileCommits(owner, repo, branch, IgnoreFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, listedCommit := range commits {
+		commit, err := m.GetCommit(owner, repo, listedCommit.GetSHA())
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range commit.Files {
+			if file.GetFilename() == IgnoreFilePath && (file.GetStatus() == "added" || file.GetStatus() == "renamed") {
+				return commit, nil
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+func (m *Manager) AttributeIgnoreFile(owner, repo, branch string) (*IgnoreFileAttribution, error) {
+	commit, err := m.GetIgnoreFileCommit(owner, repo, branch)
+	if err != nil {
+		return nil, err
+	}
+	if commit == nil {
+		return &IgnoreFileAttribution{}, nil
+	}
+
+	attribution := &IgnoreFileAttribution{
+		Commit:   commit.GetSHA(),
+		Author:   strings.ToLower(commit.GetAuthor().GetLogin()),
+		Verified: commit.GetCommit().GetVerification().GetVerified(),
+	}
+
+	pullRequests, err := m.ListPullRequestsWithCommit(owner, repo, attribution.Commit)
+	if err != nil {
+		return nil, err
+	}
+	for _, pullRequest := range pullRequests {
+		if pullRequest.MergedAt == nil || pullRequest.GetBase().GetRef() != branch {
+			continue
+		}
+		mergedPullRequest, err := m.GetPullRequest(owner, repo, pullRequest.GetNumber())
+		if err != nil {
+			return nil, err
+		}
+		attribution.MergedBy = strings.ToLower(mergedPullRequest.GetMergedBy().GetLogin())
+		break
+	}
+
+	switch {
+	case attribution.MergedBy != "" && Includes(m.IgnoreApprovers, attribution.MergedBy):
+		attribution.Approver = attribution.MergedBy
+	case attribution.Verified && attribution.Author != "" && Includes(m.IgnoreApprovers, attribution.Author):
+		attribution.Approver = attribution.Author
+	}
+
+	return attribution, nil
+}
+
+func (m *Manager) IgnoreFileApproved(owner, repo, branch string, logger *log.Entry) (bool, error) {
+	attribution, err := m.AttributeIgnoreFile(owner, repo, branch)
+	if err != nil {
+		return false, err
+	}
+
+	fields := log.Fields{
+		logging.DetailCommit:   attribution.Commit,
+		logging.DetailAuthor:   attribution.Author,
+		logging.DetailMergedBy: attribution.MergedBy,
+	}
+	if attribution.Approver != "" {
+		fields["event"] = logging.EventSkippedIgnored
+		fields[logging.DetailApprover] = attribution.Approver
+		logger.WithFields(fields).Infof("Found .emass-repo-ignore file added by approver %s, skipping repository", attribution.Approver)
+		return true, nil
+	}
+
+	fields["event"] = logging.EventUnapprovedIgnoreFile
+	if attribution.Commit == "" {
+		logger.WithFields(fields).Warnf("Found .emass-repo-ignore file but could not find the commit that added it, ignoring file")
+		return false, nil
+	}
+	if !attribution.Verified && attribution.MergedBy == "" && Includes(m.IgnoreApprovers, attribution.Author) {
+		logger.WithFields(fields).Warnf("Found .emass-repo-ignore file added in unverified commit %s, ignoring file", attribution.Commit)
+		return false, nil
+	}
+	logger.WithFields(fields).Warnf("Found .emass-repo-ignore file added in %s by a user who is not an approver, ignoring file", attribution.Commit)
+
+	return false, nil
+}
@@ -0,0 +1,220 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v52/github"
+)
+
+type testIgnoreCommit struct {
+	sha      string
+	author   string
+	verified bool
+	status   string
+}
+
+type testIgnorePullRequest struct {
+	number   int
+	base     string
+	merged   bool
+	mergedBy string
+}
+
+// newIgnoreTestManager serves the commit history of the ignore file, newest
+// first, and the pull requests each commit belongs to.
+func newIgnoreTestManager(t *testing.T, commits []testIgnoreCommit, pullRequests map[string][]testIgnorePullRequest) *Manager {
+	t.Helper()
+
+	commitsBySHA := map[string]testIgnoreCommit{}
+	for _, commit := range commits {
+		commitsBySHA[commit.sha] = commit
+	}
+	pullRequestsByNumber := map[int]testIgnorePullRequest{}
+	for _, listed := range pullRequests {
+		for _, pullRequest := range listed {
+			pullRequestsByNumber[pullRequest.number] = pullRequest
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		segments := strings.Split(strings.TrimPrefix(r.URL.Path, "/repos/org/repo/"), "/")
+		var response interface{}
+		switch {
+		case len(segments) == 1 && segments[0] == "commits":
+			if r.URL.Query().Get("path") != IgnoreFilePath || r.URL.Query().Get("sha") != "main" {
+				t.Errorf("unexpected commit list query %s", r.URL.RawQuery)
+			}
+			var listed []*github.RepositoryCommit
+			for _, commit := range commits {
+				listed = append(listed, &github.RepositoryCommit{SHA: github.String(commit.sha)})
+			}
+			response = listed
+		case len(segments) == 2 && segments[0] == "commits":
+			commit, ok := commitsBySHA[segments[1]]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			response = &github.RepositoryCommit{
+				SHA:    github.String(commit.sha),
+				Author: &github.User{Login: github.String(commit.author)},
+				Commit: &github.Commit{
+					Verification: &github.SignatureVerification{Verified: github.Bool(commit.verified)},
+				},
+				Files: []*github.CommitFile{
+					{Filename: github.String(IgnoreFilePath), Status: github.String(commit.status)},
+				},
+			}
+		case len(segments) == 3 && segments[0] == "commits" && segments[2] == "pulls":
+			listed := []*github.PullRequest{}
+			for _, pullRequest := range pullRequests[segments[1]] {
+				listed = append(listed, testPullRequest(pullRequest, false))
+			}
+			response = listed
+		case len(segments) == 2 && segments[0] == "pulls":
+			number, _ := strconv.Atoi(segments[1])
+			pullRequest, ok := pullRequestsByNumber[number]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			response = testPullRequest(pullRequest, true)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		err := json.NewEncoder(w).Encode(response)
+		if err != nil {
+			t.Errorf("failed to encode response: %v", err)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	baseURL, err := url.Parse(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to parse server URL: %v", err)
+	}
+	client.BaseURL = baseURL
+
+	return &Manager{
+		Context:           context.Background(),
+		AdminGitHubClient: client,
+		IgnoreApprovers:   []string{"approver"},
+	}
+}
+
+// testPullRequest mirrors the API, which only includes merged_by when a single
+// pull request is requested.
+func testPullRequest(pullRequest testIgnorePullRequest, detailed bool) *github.PullRequest {
+	result := &github.PullRequest{
+		Number: github.Int(pullRequest.number),
+		Base:   &github.PullRequestBranch{Ref: github.String(pullRequest.base)},
+	}
+	if pullRequest.merged {
+		result.MergedAt = &github.Timestamp{}
+		if detailed {
+			result.MergedBy = &github.User{Login: github.String(pullRequest.mergedBy)}
+		}
+	}
+
+	return result
+}
+
+func TestAttributeIgnoreFile(t *testing.T) {
+	tests := []struct {
+		name         string
+		commits      []testIgnoreCommit
+		pullRequests map[string][]testIgnorePullRequest
+		attribution  *IgnoreFileAttribution
+	}{
+		{
+			name: "merged pull request",
+			commits: []testIgnoreCommit{
+				{sha: "a1", author: "Developer", status: "added"},
+			},
+			pullRequests: map[string][]testIgnorePullRequest{
+				"a1": {{number: 7, base: "main", merged: true, mergedBy: "Approver"}},
+			},
+			attribution: &IgnoreFileAttribution{Commit: "a1", Author: "developer", MergedBy: "approver", Approver: "approver"},
+		},
+		{
+			name: "pull request merged by a user who is not an approver",
+			commits: []testIgnoreCommit{
+				{sha: "a1", author: "developer", verified: true, status: "added"},
+			},
+			pullRequests: map[string][]testIgnorePullRequest{
+				"a1": {{number: 7, base: "main", merged: true, mergedBy: "developer"}},
+			},
+			attribution: &IgnoreFileAttribution{Commit: "a1", Author: "developer", Verified: true, MergedBy: "developer"},
+		},
+		{
+			name: "pull request merged into another branch",
+			commits: []testIgnoreCommit{
+				{sha: "a1", author: "developer", status: "added"},
+			},
+			pullRequests: map[string][]testIgnorePullRequest{
+				"a1": {
+					{number: 6, base: "main"},
+					{number: 7, base: "release", merged: true, mergedBy: "approver"},
+				},
+			},
+			attribution: &IgnoreFileAttribution{Commit: "a1", Author: "developer"},
+		},
+		{
+			name: "direct verified commit",
+			commits: []testIgnoreCommit{
+				{sha: "a1", author: "Approver", verified: true, status: "added"},
+			},
+			attribution: &IgnoreFileAttribution{Commit: "a1", Author: "approver", Verified: true, Approver: "approver"},
+		},
+		{
+			name: "unverified commit",
+			commits: []testIgnoreCommit{
+				{sha: "a1", author: "approver", status: "added"},
+			},
+			attribution: &IgnoreFileAttribution{Commit: "a1", Author: "approver"},
+		},
+		{
+			name: "rename",
+			commits: []testIgnoreCommit{
+				{sha: "c3", author: "developer", verified: true, status: "modified"},
+				{sha: "b2", author: "approver", verified: true, status: "renamed"},
+				{sha: "a1", author: "developer", verified: true, status: "added"},
+			},
+			attribution: &IgnoreFileAttribution{Commit: "b2", Author: "approver", Verified: true, Approver: "approver"},
+		},
+		{
+			name: "no commit added the file",
+			commits: []testIgnoreCommit{
+				{sha: "a1", author: "approver", verified: true, status: "modified"},
+			},
+			attribution: &IgnoreFileAttribution{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m := newIgnoreTestManager(t, test.commits, test.pullRequests)
+
+			attribution, err := m.AttributeIgnoreFile("org", "repo", "main")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(attribution, test.attribution) {
+				t.Errorf("got attribution %+v, want %+v", attribution, test.attribution)
+			}
+		})
+	}
+}
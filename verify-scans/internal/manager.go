@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/department-of-veterans-affairs/codeql-tools/logging"
+	"github.com/department-of-veterans-affairs/codeql-tools/utils"
 	"github.com/google/go-github/v52/github"
 	log "github.com/sirupsen/logrus"
 )
@@ -20,30 +22,54 @@ type Manager struct {
 	VerifyScansGithubClient *github.Client
 
 	Config       *Input
-	Logger       *log.Entry
 	GlobalLogger *log.Logger
 
 	EMASSSystemIDs       []int64
+	Exemptions           *utils.ExemptionChecker
+	IgnoreApprovers      []string
 	LatestCodeQLVersions []string
 }
 
 func (m *Manager) ProcessRepository(repo *github.Repository) {
 	logger := m.GlobalLogger.WithField("repo", repo.GetName())
-	m.Logger = logger
 
 	org := repo.GetOwner().GetLogin()
 	name := repo.GetName()
 	defaultBranch := repo.GetDefaultBranch()
 
 	logger.Info("Checking if repository is ignored")
-	repoIgnored, err := m.FileExists(org, name, ".github/.emass-repo-ignore")
+	repoIgnored, err := m.FileExists(org, name, IgnoreFilePath)
 	if err != nil {
 		logger.Fatalf("failed to check if repository is ignored: %v", err)
 	}
-	if repoIgnored {
-		logger.WithField("event", "skipped-ignored").Infof("Found .emass-repo-ignore file, skipping repository")
+	if repoIgnored && m.Config.IgnoreApproversRepo == "" {
+		logger.WithField("event", logging.EventSkippedIgnored).Infof("Found .emass-repo-ignore file, skipping repository")
 		return
 	}
+	if repoIgnored {
+		logger.Infof("Checking if .emass-repo-ignore file was added by an approver")
+		approved, err := m.IgnoreFileApproved(org, name, defaultBranch, logger)
+		if err != nil {
+			logger.Errorf("failed to check if .emass-repo-ignore file was added by an approver, skipping repo: %v", err)
+			return
+		}
+		if approved {
+			return
+		}
+	}
+
+	if m.Exemptions != nil {
+		logger.Infof("Checking if repository has an approved exemption")
+		exempt, err := m.Exemptions.Exempt(org, name, logger)
+		if err != nil {
+			logger.Errorf("failed to check if repository has an approved exemption, skipping repo: %v", err)
+			return
+		}
+		if exempt {
+			return
+		}
+		logger.Debugf("Repository does not have an approved exemption")
+	}
 
 	logger.Infof("Retrieving open '%s' issues", NonCompliantLabel)
 	issues, err := m.ListOpenIssues(org, name, NonCompliantLabel)
@@ -51,7 +77,7 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		logger.Warnf("Failed to retrieve open issues, skipping closing issues: %v", err)
 	} else {
 		logger.Infof("Closing %d open issues", len(issues))
-		m.CloseIssues(org, name, issues)
+		m.CloseIssues(org, name, issues, logger)
 	}
 	logger.Debugf("Open issues retrieved")
 
@@ -70,19 +96,19 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		return
 	}
 	if emassConfig == nil || emassConfig.SystemID == 0 || emassConfig.SystemName == "" || emassConfig.SystemOwnerName == "" || emassConfig.SystemOwnerEmail == "" {
-		logger.WithField("event", "missing-configuration").Warnf(".github/emass.json not found, or missing/incorrect eMASS data")
-		logger.WithField("event", "generating-email").Infof("Sending 'Error: GitHub Repository Not Mapped To eMASS System' email to OIS and system owner")
+		logger.WithField("event", logging.EventMissingConfiguration).Warnf(".github/emass.json not found, or missing/incorrect eMASS data")
+		logger.WithField("event", logging.EventGeneratingEmail).Infof("Sending 'Error: GitHub Repository Not Mapped To eMASS System' email to OIS and system owner")
 		body := GenerateMissingEMASSEmailBody(m.Config.MissingInfoEmailTemplate, repo.GetHTMLURL())
-		err = m.SendEmail("", "Error: GitHub Repository Not Mapped To eMASS System", body)
+		err = m.SendEmail("", "Error: GitHub Repository Not Mapped To eMASS System", body, logger)
 		if err != nil {
 			logger.Errorf("failed to send email, skipping repository: %v", err)
 			return
 		}
 		logger.Debugf("Email sent")
-		logger.WithField("event", "system-owner-notified").Infof("Sent email to system owner")
+		logger.WithField("event", logging.EventSystemOwnerNotified).Infof("Sent email to system owner")
 
 		issueBody := GenerateMissingEMASSIssueBody(m.Config.MissingInfoIssueTemplate, repo.GetHTMLURL())
-		err = m.CreateIssue(org, name, "Error: GitHub Repository Not Mapped To eMASS System", issueBody, []string{NonCompliantLabel})
+		err = m.CreateIssue(org, name, "Error: GitHub Repository Not Mapped To eMASS System", issueBody, []string{NonCompliantLabel}, logger)
 		if err != nil {
 			logger.Errorf("failed to create issue, skipping repository: %v", err)
 			return
@@ -103,7 +129,7 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 	logger.Debugf("Supported CodeQL languages retrieved")
 
 	logger.Info("Retrieving recent CodeQL analyses")
-	recentAnalyses, err := m.ListCodeQLAnalyses(org, name, defaultBranch, expectedLanguages)
+	recentAnalyses, err := m.ListCodeQLAnalyses(org, name, defaultBranch, expectedLanguages, logger)
 	if err != nil {
 		logger.Errorf("failed to retrieve recent CodeQL analyses, skipping repo: %v", err)
 		return
@@ -132,18 +158,21 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 	if len(recentAnalyses.Versions) > 0 {
 		for _, version := range recentAnalyses.Versions {
 			if !Includes(m.LatestCodeQLVersions, version) {
-				logger.WithField("event", "out-of-date-cli").Warnf("Outdated CodeQL CLI version found: %s", version)
-				logger.WithField("event", "generating-email").Warnf("Sending 'GitHub Repository Code Scanning Software Is Out Of Date' email to OIS and System Owner")
+				logger.WithFields(log.Fields{
+					"event":                  logging.EventOutOfDateCLI,
+					logging.DetailCLIVersion: version,
+				}).Warnf("Outdated CodeQL CLI version found: %s", version)
+				logger.WithField("event", logging.EventGeneratingEmail).Warnf("Sending 'GitHub Repository Code Scanning Software Is Out Of Date' email to OIS and System Owner")
 				body := GenerateOutOfComplianceCLIEmailBody(m.Config.OutOfComplianceCLIEmailTemplate, name, repo.GetHTMLURL(), version)
-				err = m.SendEmail(emassConfig.SystemOwnerEmail, "GitHub Repository Code Scanning Software Is Out Of Date", body)
+				err = m.SendEmail(emassConfig.SystemOwnerEmail, "GitHub Repository Code Scanning Software Is Out Of Date", body, logger)
 				if err != nil {
 					logger.Errorf("failed to send email, skipping repository: %v", err)
 					return
 				}
-				logger.WithField("event", "system-owner-notified").Infof("Sent email to system owner")
+				logger.WithField("event", logging.EventSystemOwnerNotified).Infof("Sent email to system owner")
 				logger.Debugf("Email sent")
 
-				err = m.CreateIssue(org, name, "GitHub Repository Code Scanning Software Is Out Of Date", body, []string{NonCompliantLabel})
+				err = m.CreateIssue(org, name, "GitHub Repository Code Scanning Software Is Out Of Date", body, []string{NonCompliantLabel}, logger)
 				if err != nil {
 					logger.Errorf("failed to create issue, skipping repository: %v", err)
 					return
@@ -172,7 +201,7 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 
 	if len(missingLanguages) == 0 && len(missingDatabaseLanguages) == 0 {
 		logger.Infof("No missing analyses or databases found")
-		logger.WithField("event", "successfully-processed").Infof("Successfully processed repository")
+		logger.WithField("event", logging.EventSuccessfullyProcessed).Infof("Successfully processed repository")
 		return
 	}
 
@@ -195,15 +224,19 @@ func (m *Manager) ProcessRepository(repo *github.Repository) {
 		return
 	}
 
-	logger.WithField("event", "missing-data").Warnf("Missing analyses or databases identified: %s", string(missingDataJSON))
-	logger.WithField("event", "generating-email").Warnf("Sending 'GitHub Repository Code Scanning Not Enabled' email to OIS and system owner")
+	logger.WithFields(log.Fields{
+		"event":                        logging.EventMissingData,
+		logging.DetailMissingAnalyses:  missingData.MissingAnalyses,
+		logging.DetailMissingDatabases: missingData.MissingDatabases,
+	}).Warnf("Missing analyses or databases identified: %s", string(missingDataJSON))
+	logger.WithField("event", logging.EventGeneratingEmail).Warnf("Sending 'GitHub Repository Code Scanning Not Enabled' email to OIS and system owner")
 	missingLanguages = Unique(missingData.MissingAnalyses, missingData.MissingDatabases)
 	body := GenerateNonCompliantEmailBody(m.Config.NonCompliantEmailTemplate, repo.GetName(), emassConfig.SystemName, emassConfig.SystemID, missingLanguages)
-	err = m.SendEmail(emassConfig.SystemOwnerEmail, "GitHub Repository Code Scanning Not Enabled", body)
+	err = m.SendEmail(emassConfig.SystemOwnerEmail, "GitHub Repository Code Scanning Not Enabled", body, logger)
 	if err != nil {
 		logger.Errorf("failed to send email, skipping repository: %v", err)
 		return
 	}
-	logger.WithField("event", "system-owner-notified").Infof("Sent email to system owner")
+	logger.WithField("event", logging.EventSystemOwnerNotified).Infof("Sent email to system owner")
 	logger.Debugf("Email sent")
 }
@@ -6,7 +6,11 @@ import (
 
 type Input struct {
 	AdminToken                      string
+	Concurrency                     int
 	DaysToScan                      int
+	Enterprise                      bool
+	EventsPath                      string
+	ExemptionProperty               string
 	EMASSPromotionAppID             int64
 	EMASSPromotionPrivateKey        []byte
 	EMASSPromotionInstallationID    int64
@@ -15,12 +19,16 @@ type Input struct {
 	GmailFrom                       string
 	GmailUser                       string
 	GmailPassword                   string
+	IgnoreApproversPath             string
+	IgnoreApproversRepo             string
 	MissingInfoEmailTemplate        string
 	MissingInfoIssueTemplate        string
 	NonCompliantEmailTemplate       string
 	Org                             string
+	OrgOverrides                    map[string]map[string]string
 	OutOfComplianceCLIEmailTemplate string
 	Repo                            string
+	ReportPath                      string
 	SecondaryEmail                  string
 	VerifyScansAppID                int64
 	VerifyScansPrivateKey           []byte